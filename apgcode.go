@@ -0,0 +1,207 @@
+package life
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Number of rows which are encoded together in the extended Wechsler format
+const wechslerStripHeight = 5
+
+// Digits of the extended Wechsler format. Each of the first 32 is a column of a strip.
+// Runs of empty columns are shortened with w, x and y, and strips are separated by z.
+const wechslerDigits = "0123456789abcdefghijklmnopqrstuvwxyz"
+
+// wechslerZeros returns the shortest encoding of the given number of empty columns
+func wechslerZeros(count int) string {
+	var buf bytes.Buffer
+	for count > 0 {
+		switch {
+		case count >= 4:
+			run := count - 4
+			if run >= len(wechslerDigits) {
+				run = len(wechslerDigits) - 1
+			}
+			buf.WriteByte('y')
+			buf.WriteByte(wechslerDigits[run])
+			count -= run + 4
+		case count == 3:
+			buf.WriteByte('x')
+			count = 0
+		case count == 2:
+			buf.WriteByte('w')
+			count = 0
+		default:
+			buf.WriteByte('0')
+			count = 0
+		}
+	}
+	return buf.String()
+}
+
+// encodeWechsler encodes the shape of the living organisms in the extended Wechsler format
+func encodeWechsler(living []Location) string {
+	origin, dims := BoundingBox(living)
+
+	columns := make([][]int, (dims.Height+wechslerStripHeight-1)/wechslerStripHeight)
+	for i := range columns {
+		columns[i] = make([]int, dims.Width)
+	}
+	for _, loc := range living {
+		y := loc.Y - origin.Y
+		columns[y/wechslerStripHeight][loc.X-origin.X] |= 1 << uint(y%wechslerStripHeight)
+	}
+
+	strips := make([]string, len(columns))
+	for i, strip := range columns {
+		var buf bytes.Buffer
+		zeros := 0
+		for _, column := range strip {
+			if column == 0 {
+				zeros++
+				continue
+			}
+			buf.WriteString(wechslerZeros(zeros))
+			zeros = 0
+			buf.WriteByte(wechslerDigits[column])
+		}
+		strips[i] = buf.String()
+	}
+
+	return strings.Join(strips, "z")
+}
+
+// decodeWechsler returns the living organisms of a pattern in the extended Wechsler format
+func decodeWechsler(code string) ([]Location, error) {
+	living := make([]Location, 0)
+
+	x, strip := 0, 0
+	for i := 0; i < len(code); i++ {
+		c := code[i]
+		switch {
+		case c == 'w':
+			x += 2
+		case c == 'x':
+			x += 3
+		case c == 'y':
+			i++
+			if i >= len(code) {
+				return nil, errors.New("missing length of run of empty columns")
+			}
+			run := strings.IndexByte(wechslerDigits, code[i])
+			if run < 0 {
+				return nil, fmt.Errorf("invalid length of run of empty columns '%c'", code[i])
+			}
+			x += run + 4
+		case c == 'z':
+			strip++
+			x = 0
+		default:
+			column := strings.IndexByte(wechslerDigits[:32], c)
+			if column < 0 {
+				return nil, fmt.Errorf("unexpected character '%c'", c)
+			}
+			for row := 0; row < wechslerStripHeight; row++ {
+				if column&(1<<uint(row)) != 0 {
+					living = append(living, Location{X: x, Y: (strip * wechslerStripHeight) + row})
+				}
+			}
+			x++
+		}
+	}
+
+	return living, nil
+}
+
+// lessApgcode tests if the first code is preferred over the second, being shorter or, at the same length, sorting first
+func lessApgcode(lhs, rhs string) bool {
+	if len(lhs) != len(rhs) {
+		return len(lhs) < len(rhs)
+	}
+	return lhs < rhs
+}
+
+// Apgcode returns the apgcode of the object, such as xs4_33 for a block, xp2_7 for a blinker or xq4_153 for a glider.
+// The object is run under the given rules, with all neighbors, until it returns to its shape to determine whether
+// it is a still life, an oscillator or a spaceship. The code is of the phase and orientation with the shortest,
+// and then alphabetically first, encoding.
+func Apgcode(object []Location, rules func(int, bool) bool, maxPeriod int) (string, error) {
+	phases, velocity, periodic := evolveObject(object, NeighborsAll, rules, maxPeriod)
+	if !periodic {
+		return "", fmt.Errorf("object did not repeat within %d generations", maxPeriod)
+	}
+
+	var prefix string
+	switch {
+	case velocity.Displacement != (Location{}):
+		prefix = "xq" + strconv.Itoa(velocity.Period)
+	case velocity.Period == 1:
+		prefix = "xs" + strconv.Itoa(len(object))
+	default:
+		prefix = "xp" + strconv.Itoa(velocity.Period)
+	}
+
+	var canonical string
+	for _, phase := range phases {
+		for _, oriented := range Symmetries(phase) {
+			if code := encodeWechsler(oriented); canonical == "" || lessApgcode(code, canonical) {
+				canonical = code
+			}
+		}
+	}
+
+	return prefix + "_" + canonical, nil
+}
+
+// ParseApgcode returns the living organisms of the object with the given apgcode
+func ParseApgcode(code string) ([]Location, error) {
+	parts := strings.SplitN(code, "_", 2)
+	if len(parts) != 2 || len(parts[0]) < 3 {
+		return nil, fmt.Errorf("malformed apgcode %q", code)
+	}
+
+	num, err := strconv.Atoi(parts[0][2:])
+	switch parts[0][:2] {
+	case "xs", "xp", "xq":
+		if err != nil || num < 0 {
+			return nil, fmt.Errorf("invalid apgcode prefix %q", parts[0])
+		}
+	default:
+		return nil, fmt.Errorf("unsupported apgcode prefix %q", parts[0])
+	}
+
+	living, err := decodeWechsler(parts[1])
+	if err != nil {
+		return nil, fmt.Errorf("invalid apgcode %q: %s", code, err)
+	}
+	if len(living) == 0 {
+		return nil, fmt.Errorf("apgcode %q has no living organisms", code)
+	}
+
+	// The number of a still life is its population
+	if parts[0][:2] == "xs" && len(living) != num {
+		return nil, fmt.Errorf("apgcode %q has %d living organisms instead of %d", code, len(living), num)
+	}
+
+	return living, nil
+}
+
+// CensusApgcodes splits the living organisms into objects, using the given connectivity distance, and counts
+// how many there are of each apgcode under the given rules. Objects which do not repeat within the given number
+// of generations are counted as "unknown".
+func CensusApgcodes(living []Location, distance int, rules func(int, bool) bool, maxPeriod int) map[string]int {
+	counts := make(map[string]int)
+	for _, object := range SplitObjects(living, distance) {
+		if code, err := Apgcode(object, rules, maxPeriod); err == nil {
+			counts[code]++
+		} else {
+			counts[censusUnknown]++
+		}
+	}
+	return counts
+}
+
+// vim: set foldmethod=marker:
@@ -0,0 +1,109 @@
+package life
+
+import "testing"
+
+func TestApgcode(t *testing.T) {
+	lwss := []Location{{X: 1, Y: 0}, {X: 4, Y: 0}, {X: 0, Y: 1}, {X: 0, Y: 2}, {X: 4, Y: 2},
+		{X: 0, Y: 3}, {X: 1, Y: 3}, {X: 2, Y: 3}, {X: 3, Y: 3}}
+
+	tests := []struct {
+		object   []Location
+		expected string
+	}{
+		{Blocks(Dimensions{Width: 5, Height: 5}, Location{X: 7, Y: -2}), "xs4_33"},
+		{Beehive(Dimensions{Width: 4, Height: 4}, Location{}), "xs6_696"},
+		{Loaf(Dimensions{Width: 4, Height: 4}, Location{}), "xs7_2596"},
+		{Boat(Dimensions{Width: 3, Height: 3}, Location{}), "xs5_253"},
+		{Blinkers(Dimensions{Width: 4, Height: 4}, Location{}), "xp2_7"},
+		{Toads(Dimensions{Width: 5, Height: 5}, Location{}), "xp2_7e"},
+		{Beacons(Dimensions{Width: 5, Height: 5}, Location{}), "xp2_318c"},
+		{Pulsar(Dimensions{Width: 16, Height: 16}, Location{}), "xp3_co9nas0san9oczgoldlo0oldlogz1047210127401"},
+		{Gliders(Dimensions{Width: 4, Height: 4}, Location{}), "xq4_153"},
+		{lwss, "xq4_6frc"},
+	}
+
+	for _, test := range tests {
+		actual, err := Apgcode(test.object, ConwayTester(), 10)
+		if err != nil {
+			t.Fatalf("Unable to encode %s: %s\n", test.expected, err)
+		}
+		if actual != test.expected {
+			t.Errorf("Encoded %s instead of %s\n", actual, test.expected)
+		}
+	}
+}
+
+func TestApgcodeNotPeriodic(t *testing.T) {
+	// The R-pentomino takes over a thousand generations to settle
+	rpentomino := []Location{{X: 1, Y: 0}, {X: 2, Y: 0}, {X: 0, Y: 1}, {X: 1, Y: 1}, {X: 1, Y: 2}}
+	if _, err := Apgcode(rpentomino, ConwayTester(), 10); err == nil {
+		t.Fatal("Encoded an object which does not repeat")
+	}
+}
+
+func TestParseApgcode(t *testing.T) {
+	living, err := ParseApgcode("xq4_153")
+	if err != nil {
+		t.Fatalf("Unable to parse apgcode: %s\n", err)
+	}
+	expected := []Location{{X: 0, Y: 0}, {X: 1, Y: 0}, {X: 2, Y: 0}, {X: 2, Y: 1}, {X: 1, Y: 2}}
+	testLocationsMatch(t, expected, living)
+
+	// Every code decodes into an object which encodes back into the same code
+	for _, code := range []string{"xs4_33", "xp2_7", "xp2_318c", "xq4_6frc", "xp3_co9nas0san9oczgoldlo0oldlogz1047210127401"} {
+		object, err := ParseApgcode(code)
+		if err != nil {
+			t.Fatalf("Unable to parse apgcode %s: %s\n", code, err)
+		}
+		actual, err := Apgcode(object, ConwayTester(), 10)
+		if err != nil {
+			t.Fatalf("Unable to encode %s: %s\n", code, err)
+		}
+		if actual != code {
+			t.Errorf("Encoded %s instead of %s\n", actual, code)
+		}
+	}
+}
+
+func TestParseApgcodeErrors(t *testing.T) {
+	for _, code := range []string{"", "xs4", "ov_s23", "xsa_33", "xs4_3!", "xs4_3y", "xs4_zzz", "xp2_0", "xs5_33", "xs3_33"} {
+		if _, err := ParseApgcode(code); err == nil {
+			t.Errorf("Parsed the invalid apgcode %q\n", code)
+		}
+	}
+}
+
+func TestWechslerZeros(t *testing.T) {
+	tests := map[int]string{0: "", 1: "0", 2: "w", 3: "x", 4: "y0", 13: "y9", 14: "ya", 39: "yz", 40: "yz0", 45: "yzy2"}
+	for count, expected := range tests {
+		if actual := wechslerZeros(count); actual != expected {
+			t.Errorf("Encoded %d empty columns as %q instead of %q\n", count, actual, expected)
+		}
+	}
+
+	// Empty columns and strips
+	living := []Location{{X: 0, Y: 0}, {X: 44, Y: 0}, {X: 0, Y: 10}}
+	code := encodeWechsler(living)
+	if expected := "1yzy01zz1"; code != expected {
+		t.Fatalf("Encoded %q instead of %q\n", code, expected)
+	}
+	decoded, err := decodeWechsler(code)
+	if err != nil {
+		t.Fatalf("Unable to decode %q: %s\n", code, err)
+	}
+	testLocationsMatch(t, living, decoded)
+}
+
+func TestCensusApgcodes(t *testing.T) {
+	living := make([]Location, 0)
+	living = append(living, Blocks(Dimensions{Width: 5, Height: 5}, Location{X: 0, Y: 0})...)
+	living = append(living, Blinkers(Dimensions{Width: 4, Height: 4}, Location{X: 20, Y: 0})...)
+	living = append(living, Blinkers(Dimensions{Width: 4, Height: 4}, Location{X: 30, Y: 0})...)
+
+	counts := CensusApgcodes(living, 1, ConwayTester(), 10)
+	if len(counts) != 2 || counts["xs4_33"] != 1 || counts["xp2_7"] != 2 {
+		t.Fatalf("Census found %v\n", counts)
+	}
+}
+
+// vim: set foldmethod=marker:
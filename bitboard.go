@@ -0,0 +1,396 @@
+package life
+
+import (
+	"math/bits"
+)
+
+// bitboard keeps track of the living organisms as bits packed into 64-bit words.
+// Each row of the board is made up of the same number of words.
+// The board grows as needed to hold any location it is given.
+type bitboard struct {
+	origin Location // The location of the first bit of the board
+	dims   Dimensions
+	stride int // Number of words in a row
+	words  []uint64
+}
+
+func wordsForBits(n int) int {
+	return (n + 63) / 64
+}
+
+// readBits returns the 64 bits of the row starting at the given bit offset.
+// Bits which are outside of the row are zero.
+func readBits(row []uint64, offset int) uint64 {
+	word := offset >> 6
+	shift := uint(offset & 63)
+
+	var val uint64
+	if word >= 0 && word < len(row) {
+		val = row[word] >> shift
+	}
+	if shift != 0 && word+1 >= 0 && word+1 < len(row) {
+		val |= row[word+1] << (64 - shift)
+	}
+	return val
+}
+
+// writeBits replaces the given number of bits (up to 64) of the row starting at the given bit offset
+func writeBits(row []uint64, offset int, val uint64, n int) {
+	mask := ^uint64(0)
+	if n < 64 {
+		mask = (uint64(1) << uint(n)) - 1
+	}
+	val &= mask
+
+	word := offset >> 6
+	shift := uint(offset & 63)
+
+	row[word] = (row[word] &^ (mask << shift)) | (val << shift)
+	if shift != 0 && int(shift)+n > 64 {
+		row[word+1] = (row[word+1] &^ (mask >> (64 - shift))) | (val >> (64 - shift))
+	}
+}
+
+func (t *bitboard) row(y int) []uint64 {
+	start := (y - t.origin.Y) * t.stride
+	return t.words[start : start+t.stride]
+}
+
+func (t *bitboard) contains(location Location) bool {
+	return location.X >= t.origin.X && location.X < t.origin.X+t.dims.Width &&
+		location.Y >= t.origin.Y && location.Y < t.origin.Y+t.dims.Height
+}
+
+// copyFrom copies the bits of the given area of the source board into this one.
+// Any of the area which is not on the source board is cleared.
+func (t *bitboard) copyFrom(src *bitboard, origin Location, dims Dimensions) {
+	for y := origin.Y; y < origin.Y+dims.Height; y++ {
+		dst := t.row(y)
+		dstOffset := origin.X - t.origin.X
+
+		var srcRow []uint64
+		if y >= src.origin.Y && y < src.origin.Y+src.dims.Height {
+			srcRow = src.row(y)
+		}
+		srcOffset := origin.X - src.origin.X
+
+		for i := 0; i < dims.Width; i += 64 {
+			n := dims.Width - i
+			if n > 64 {
+				n = 64
+			}
+
+			var val uint64
+			if srcRow != nil {
+				val = readBits(srcRow, srcOffset+i)
+
+				// Ignore the bits which are past the end of the source board's row
+				if remaining := src.dims.Width - (srcOffset + i); remaining < 64 {
+					if remaining <= 0 {
+						val = 0
+					} else {
+						val &= (uint64(1) << uint(remaining)) - 1
+					}
+				}
+				// Ignore the bits which are before the start of the source board's row
+				if srcOffset+i < 0 {
+					if -(srcOffset + i) >= 64 {
+						val = 0
+					} else {
+						val &^= (uint64(1) << uint(-(srcOffset + i))) - 1
+					}
+				}
+			}
+
+			writeBits(dst, dstOffset+i, val, n)
+		}
+	}
+}
+
+// grow resizes the board so that it includes the given location
+func (t *bitboard) grow(location Location) {
+	min := t.origin
+	max := Location{X: t.origin.X + t.dims.Width, Y: t.origin.Y + t.dims.Height}
+
+	// Leave room to grow so that boards which grow a cell at a time are not constantly resized
+	if location.X < min.X {
+		min.X = location.X - t.dims.Width/2
+	}
+	if location.Y < min.Y {
+		min.Y = location.Y - t.dims.Height/2
+	}
+	if location.X >= max.X {
+		max.X = location.X + 1 + t.dims.Width/2
+	}
+	if location.Y >= max.Y {
+		max.Y = location.Y + 1 + t.dims.Height/2
+	}
+
+	grown := newBitboard(min, Dimensions{Width: max.X - min.X, Height: max.Y - min.Y})
+	grown.copyFrom(t, t.origin, t.dims)
+
+	*t = *grown
+}
+
+func (t *bitboard) Set(location Location) bool {
+	if !t.contains(location) {
+		t.grow(location)
+	}
+
+	x := location.X - t.origin.X
+	t.row(location.Y)[x>>6] |= uint64(1) << uint(x&63)
+
+	return true
+}
+
+func (t *bitboard) Remove(location Location) bool {
+	if !t.Test(location) {
+		return false
+	}
+
+	x := location.X - t.origin.X
+	t.row(location.Y)[x>>6] &^= uint64(1) << uint(x&63)
+
+	return true
+}
+
+func (t *bitboard) Test(location Location) bool {
+	if !t.contains(location) {
+		return false
+	}
+
+	x := location.X - t.origin.X
+	return t.row(location.Y)[x>>6]&(uint64(1)<<uint(x&63)) != 0
+}
+
+func (t *bitboard) GetAll() []Location {
+	all := make([]Location, 0, t.Count())
+
+	for y := t.origin.Y; y < t.origin.Y+t.dims.Height; y++ {
+		for i, word := range t.row(y) {
+			for word != 0 {
+				bit := bits.TrailingZeros64(word)
+				all = append(all, Location{X: t.origin.X + (i * 64) + bit, Y: y})
+				word &= word - 1
+			}
+		}
+	}
+
+	return all
+}
+
+func (t *bitboard) Count() int {
+	count := 0
+	for _, word := range t.words {
+		count += bits.OnesCount64(word)
+	}
+	return count
+}
+
+func (t *bitboard) Equals(rhs cellStore) bool {
+	if t.Count() != rhs.Count() {
+		return false
+	}
+
+	for _, loc := range t.GetAll() {
+		if !rhs.Test(loc) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (t *bitboard) Clone() cellStore {
+	shadow := newBitboard(t.origin, t.dims)
+	copy(shadow.words, t.words)
+	return shadow
+}
+
+// neighborPlanes fills in, for each neighbor position, the bits of that neighbor of the cells of the middle row.
+// Returns the number of planes which were filled in.
+func neighborPlanes(planes *[8]uint64, selector neighborsSelector, above, middle, below []uint64, word int) int {
+	// The words on either side of the given word
+	aboveWest, middleWest, belowWest := uint64(0), uint64(0), uint64(0)
+	if word > 0 {
+		aboveWest, middleWest, belowWest = above[word-1]>>63, middle[word-1]>>63, below[word-1]>>63
+	}
+	aboveEast, middleEast, belowEast := uint64(0), uint64(0), uint64(0)
+	if word+1 < len(middle) {
+		aboveEast, middleEast, belowEast = above[word+1]<<63, middle[word+1]<<63, below[word+1]<<63
+	}
+
+	n := 0
+	if selector != NeighborsOblique {
+		planes[0] = above[word]
+		planes[1] = below[word]
+		planes[2] = (middle[word] << 1) | middleWest
+		planes[3] = (middle[word] >> 1) | middleEast
+		n = 4
+	}
+	if selector != NeighborsOrthogonal {
+		planes[n] = (above[word] << 1) | aboveWest
+		planes[n+1] = (above[word] >> 1) | aboveEast
+		planes[n+2] = (below[word] << 1) | belowWest
+		planes[n+3] = (below[word] >> 1) | belowEast
+		n += 4
+	}
+	return n
+}
+
+// countNeighbors adds up the neighbor planes into a bit-sliced count of up to 8
+func countNeighbors(planes []uint64) [4]uint64 {
+	var count [4]uint64
+	for _, plane := range planes {
+		carry0 := count[0] & plane
+		count[0] ^= plane
+		carry1 := count[1] & carry0
+		count[1] ^= carry0
+		carry2 := count[2] & carry1
+		count[2] ^= carry1
+		count[3] |= carry2 // Can only be reached by the eighth neighbor
+	}
+	return count
+}
+
+// bitboardRules are the outcomes of the rules for every possible number of neighbors
+type bitboardRules struct {
+	survive [9]bool
+	born    [9]bool
+	counts  []int // The numbers of neighbors which lead to a living organism
+}
+
+func newBitboardRules(rules func(int, bool) bool) *bitboardRules {
+	table := new(bitboardRules)
+	for count := 0; count <= 8; count++ {
+		table.survive[count] = rules(count, true)
+		table.born[count] = rules(count, false)
+	}
+
+	// Only organisms which are alive or neighbor a living organism are considered
+	// by the other processors, so nothing can be born without any neighbors
+	table.born[0] = false
+
+	for count := 0; count <= 8; count++ {
+		if table.survive[count] || table.born[count] {
+			table.counts = append(table.counts, count)
+		}
+	}
+
+	return table
+}
+
+// next computes the next state of a word of cells from its current state and bit-sliced neighbor count
+func (t *bitboardRules) next(current uint64, count [4]uint64) uint64 {
+	var next uint64
+	for _, num := range t.counts {
+		matches := ^uint64(0)
+		for i, plane := range count {
+			if num&(1<<uint(i)) != 0 {
+				matches &= plane
+			} else {
+				matches &^= plane
+			}
+		}
+
+		if t.survive[num] {
+			next |= matches & current
+		}
+		if t.born[num] {
+			next |= matches &^ current
+		}
+	}
+	return next
+}
+
+// step computes the next generation of the given pond, whose organisms are stored in the bitboard
+func (t *bitboard) step(pond *pond, rules *bitboardRules) {
+	// Determine which area can change
+	origin, dims := Location{}, pond.Dims
+	if pond.topology == TopologyUnbounded {
+		living := t.GetAll()
+		if len(living) == 0 {
+			return
+		}
+		origin, dims = BoundingBox(living)
+		origin = Location{X: origin.X - 1, Y: origin.Y - 1}
+		dims = Dimensions{Width: dims.Width + 2, Height: dims.Height + 2}
+	}
+
+	// Copy the area into a board which is padded by a cell on each side
+	padded := newBitboard(Location{X: origin.X - 1, Y: origin.Y - 1},
+		Dimensions{Width: dims.Width + 2, Height: dims.Height + 2})
+	padded.copyFrom(t, origin, dims)
+
+	// Fill in the padding with whatever the topology says is past the edges
+	if pond.topology != TopologyUnbounded {
+		for x := origin.X - 1; x <= origin.X+dims.Width; x++ {
+			for _, y := range []int{origin.Y - 1, origin.Y + dims.Height} {
+				if resolved, valid := pond.resolveLocation(Location{X: x, Y: y}); valid && t.Test(resolved) {
+					padded.Set(Location{X: x, Y: y})
+				}
+			}
+		}
+		for y := origin.Y; y < origin.Y+dims.Height; y++ {
+			for _, x := range []int{origin.X - 1, origin.X + dims.Width} {
+				if resolved, valid := pond.resolveLocation(Location{X: x, Y: y}); valid && t.Test(resolved) {
+					padded.Set(Location{X: x, Y: y})
+				}
+			}
+		}
+	}
+
+	// Now compute every row of the next generation
+	var planes [8]uint64
+	next := newBitboard(padded.origin, padded.dims)
+	for y := origin.Y; y < origin.Y+dims.Height; y++ {
+		above, middle, below := padded.row(y-1), padded.row(y), padded.row(y+1)
+		row := next.row(y)
+		for word := range row {
+			n := neighborPlanes(&planes, pond.neighborsSelector, above, middle, below, word)
+			row[word] = rules.next(middle[word], countNeighbors(planes[:n]))
+		}
+	}
+
+	// Only the area itself is kept, without the padding
+	result := newBitboard(origin, dims)
+	result.copyFrom(next, origin, dims)
+
+	if pond.changes != nil {
+		t.recordChanges(pond.changes, result)
+	}
+
+	*t = *result
+}
+
+// recordChanges adds the organisms which differ between this board and the given board,
+// which must cover all of the organisms of this board, to the change set
+func (t *bitboard) recordChanges(changes *changeSet, next *bitboard) {
+	previous := newBitboard(next.origin, next.dims)
+	previous.copyFrom(t, next.origin, next.dims)
+
+	for y := next.origin.Y; y < next.origin.Y+next.dims.Height; y++ {
+		before, after := previous.row(y), next.row(y)
+		for i, word := range after {
+			diff := word ^ before[i]
+			for diff != 0 {
+				bit := bits.TrailingZeros64(diff)
+				changes.record(Location{X: next.origin.X + (i * 64) + bit, Y: y}, word&(uint64(1)<<uint(bit)) != 0)
+				diff &= diff - 1
+			}
+		}
+	}
+}
+
+func newBitboard(origin Location, dims Dimensions) *bitboard {
+	t := new(bitboard)
+
+	t.origin = origin
+	t.dims = dims
+	t.stride = wordsForBits(dims.Width)
+	t.words = make([]uint64, t.stride*dims.Height)
+
+	return t
+}
+
+// vim: set foldmethod=marker:
@@ -0,0 +1,92 @@
+package life
+
+import "testing"
+
+func TestBitboardSetTest(t *testing.T) {
+	board := newBitboard(Location{}, Dimensions{Width: 100, Height: 3})
+
+	loc := Location{X: 70, Y: 2}
+	board.Set(loc)
+
+	if !board.Test(loc) {
+		t.Fatal("Added location unexpectedly tested false")
+	}
+	if board.Test(Location{X: 6, Y: 2}) {
+		t.Fatal("Unexpectedly tested true a location that was not added")
+	}
+}
+
+func TestBitboardRemove(t *testing.T) {
+	board := newBitboard(Location{}, Dimensions{Width: 3, Height: 3})
+
+	loc := Location{X: 1, Y: 1}
+	board.Set(loc)
+
+	if !board.Remove(loc) {
+		t.Fatal("Unable to remove a location that was added")
+	}
+	if board.Test(loc) {
+		t.Fatal("Unexpectedly tested true a location that was removed")
+	}
+	if board.Remove(loc) {
+		t.Fatal("Unexpectedly able to remove a location that doesn't exist")
+	}
+}
+
+func TestBitboardGrow(t *testing.T) {
+	board := newBitboard(Location{}, Dimensions{})
+
+	expected := Random(Dimensions{Width: 150, Height: 20}, Location{X: -75, Y: -10}, 30)
+	for _, loc := range expected {
+		board.Set(loc)
+	}
+
+	if board.Count() != len(expected) {
+		t.Fatalf("Counted %d locations instead of %d\n", board.Count(), len(expected))
+	}
+
+	testLocationsMatch(t, expected, board.GetAll())
+}
+
+func TestBitboardEqualsClone(t *testing.T) {
+	board := newBitboard(Location{}, Dimensions{Width: 80, Height: 8})
+	for _, loc := range Random(Dimensions{Width: 80, Height: 8}, Location{}, 50) {
+		board.Set(loc)
+	}
+
+	clone := board.Clone()
+	if !board.Equals(clone) || !clone.Equals(board) {
+		t.Fatal("Clone is not equal to the original")
+	}
+
+	tracker := newTracker()
+	for _, loc := range board.GetAll() {
+		tracker.Set(loc)
+	}
+	if !board.Equals(tracker) || !tracker.Equals(board) {
+		t.Fatal("Bitboard is not equal to a tracker with the same locations")
+	}
+
+	clone.Set(Location{X: 100, Y: 100})
+	if board.Equals(clone) {
+		t.Fatal("Modified clone is still equal to the original")
+	}
+}
+
+func TestReadWriteBits(t *testing.T) {
+	row := make([]uint64, 3)
+
+	writeBits(row, 60, 0xFF, 8)
+	if readBits(row, 60)&0xFF != 0xFF {
+		t.Fatalf("Read %x instead of ff across a word boundary\n", readBits(row, 60)&0xFF)
+	}
+	if row[0] != 0xF<<60 || row[1] != 0xF {
+		t.Fatalf("Bits were written to the wrong positions: %x %x\n", row[0], row[1])
+	}
+
+	if readBits(row, -4)&0xF != 0 {
+		t.Fatal("Bits before the start of the row were not zero")
+	}
+}
+
+// vim: set foldmethod=marker:
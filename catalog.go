@@ -0,0 +1,177 @@
+package life
+
+import (
+	"errors"
+	"strings"
+	"sync"
+)
+
+type patternCategory int
+
+// Enumeration of the kinds of patterns in the catalog
+const (
+	CategoryStillLife patternCategory = iota
+	CategoryOscillator
+	CategorySpaceship
+	CategoryGun
+	CategoryPuffer
+	CategoryMethuselah
+)
+
+func (t patternCategory) String() string {
+	switch t {
+	case CategoryStillLife:
+		return "Still life"
+	case CategoryOscillator:
+		return "Oscillator"
+	case CategorySpaceship:
+		return "Spaceship"
+	case CategoryGun:
+		return "Gun"
+	case CategoryPuffer:
+		return "Puffer"
+	case CategoryMethuselah:
+		return "Methuselah"
+	}
+	return "Unknown"
+}
+
+// CatalogPattern describes a well-known pattern of Conway's rules
+type CatalogPattern struct {
+	Name       string
+	Aliases    []string
+	Category   patternCategory
+	Period     int        // Zero for patterns which do not repeat
+	Lifespan   int        // The number of generations a methuselah takes to stabilize
+	Dims       Dimensions // The smallest bounding box of any of the phases of the pattern
+	Discoverer string     // Empty when not known
+
+	// Fill generates as many copies of the pattern as fit on a board of the given size
+	Fill func(Dimensions, Location) []Location
+	// Board size on which Fill generates a single copy of the pattern
+	single Dimensions
+}
+
+// Living returns the organisms of a single copy of the pattern, at the origin
+func (t *CatalogPattern) Living() []Location {
+	return Normalize(t.Fill(t.single, Location{}))
+}
+
+// Initializer returns a function which can be given to New to seed a Life with a single copy of the pattern
+func (t *CatalogPattern) Initializer() func(Dimensions, Location) []Location {
+	living := t.Living()
+	return func(dimensions Dimensions, offset Location) []Location {
+		return Translate(living, offset)
+	}
+}
+
+// names returns the name and all of the aliases of the pattern
+func (t *CatalogPattern) names() []string {
+	return append([]string{t.Name}, t.Aliases...)
+}
+
+var (
+	catalogMutex sync.RWMutex
+	catalog      = []*CatalogPattern{
+		{Name: "block", Aliases: []string{"blocks"}, Category: CategoryStillLife, Period: 1,
+			Dims: Dimensions{Width: 2, Height: 2}, Discoverer: "John Conway", Fill: Blocks, single: Dimensions{Width: 5, Height: 5}},
+		{Name: "beehive", Aliases: []string{"beehives", "hive"}, Category: CategoryStillLife, Period: 1,
+			Dims: Dimensions{Width: 4, Height: 3}, Discoverer: "John Conway", Fill: Beehive, single: Dimensions{Width: 4, Height: 4}},
+		{Name: "loaf", Aliases: []string{"loaves"}, Category: CategoryStillLife, Period: 1,
+			Dims: Dimensions{Width: 4, Height: 4}, Fill: Loaf, single: Dimensions{Width: 4, Height: 4}},
+		{Name: "boat", Aliases: []string{"boats"}, Category: CategoryStillLife, Period: 1,
+			Dims: Dimensions{Width: 3, Height: 3}, Discoverer: "John Conway", Fill: Boat, single: Dimensions{Width: 3, Height: 3}},
+		{Name: "blinker", Aliases: []string{"blinkers"}, Category: CategoryOscillator, Period: 2,
+			Dims: Dimensions{Width: 3, Height: 1}, Discoverer: "John Conway", Fill: Blinkers, single: Dimensions{Width: 4, Height: 4}},
+		{Name: "toad", Aliases: []string{"toads"}, Category: CategoryOscillator, Period: 2,
+			Dims: Dimensions{Width: 4, Height: 2}, Discoverer: "Simon Norton", Fill: Toads, single: Dimensions{Width: 5, Height: 5}},
+		{Name: "beacon", Aliases: []string{"beacons"}, Category: CategoryOscillator, Period: 2,
+			Dims: Dimensions{Width: 4, Height: 4}, Discoverer: "John Conway", Fill: Beacons, single: Dimensions{Width: 5, Height: 5}},
+		{Name: "pulsar", Aliases: []string{"pulsars"}, Category: CategoryOscillator, Period: 3,
+			Dims: Dimensions{Width: 13, Height: 13}, Discoverer: "John Conway", Fill: Pulsar, single: Dimensions{Width: 16, Height: 16}},
+		{Name: "pentadecathlon", Aliases: []string{"pentadecathlons"}, Category: CategoryOscillator, Period: 15,
+			Dims: Dimensions{Width: 8, Height: 3}, Discoverer: "John Conway", Fill: Pentadecathlon, single: Dimensions{Width: 10, Height: 3}},
+		{Name: "queen bee shuttle", Aliases: []string{"queenbeeshuttle", "queen bee shuttles"}, Category: CategoryOscillator, Period: 30,
+			Dims: Dimensions{Width: 22, Height: 7}, Discoverer: "Bill Gosper", Fill: QueenBeeShuttle, single: Dimensions{Width: 22, Height: 7}},
+		{Name: "glider", Aliases: []string{"gliders"}, Category: CategorySpaceship, Period: 4,
+			Dims: Dimensions{Width: 3, Height: 3}, Discoverer: "Richard K. Guy", Fill: Gliders, single: Dimensions{Width: 4, Height: 4}},
+		{Name: "lwss", Aliases: []string{"lightweight spaceship", "lightweightspaceship"}, Category: CategorySpaceship, Period: 4,
+			Dims: Dimensions{Width: 5, Height: 4}, Discoverer: "John Conway", Fill: LightweightSpaceship, single: Dimensions{Width: 5, Height: 4}},
+		{Name: "mwss", Aliases: []string{"middleweight spaceship", "middleweightspaceship"}, Category: CategorySpaceship, Period: 4,
+			Dims: Dimensions{Width: 6, Height: 4}, Discoverer: "John Conway", Fill: MiddleweightSpaceship, single: Dimensions{Width: 6, Height: 5}},
+		{Name: "hwss", Aliases: []string{"heavyweight spaceship", "heavyweightspaceship"}, Category: CategorySpaceship, Period: 4,
+			Dims: Dimensions{Width: 7, Height: 4}, Discoverer: "John Conway", Fill: HeavyweightSpaceship, single: Dimensions{Width: 7, Height: 5}},
+		{Name: "gosper glider gun", Aliases: []string{"gosper", "gosperglidergun"}, Category: CategoryGun, Period: 30,
+			Dims: Dimensions{Width: 36, Height: 9}, Discoverer: "Bill Gosper", Fill: GosperGliderGun, single: Dimensions{Width: 36, Height: 9}},
+		{Name: "simkin glider gun", Aliases: []string{"simkin", "simkinglidergun"}, Category: CategoryGun, Period: 120,
+			Dims: Dimensions{Width: 33, Height: 21}, Discoverer: "Michael Simkin", Fill: SimkinGliderGun, single: Dimensions{Width: 33, Height: 21}},
+		{Name: "puffer train", Aliases: []string{"puffertrain"}, Category: CategoryPuffer, Period: 140,
+			Dims: Dimensions{Width: 5, Height: 18}, Discoverer: "Bill Gosper", Fill: PufferTrain, single: Dimensions{Width: 5, Height: 18}},
+		{Name: "r-pentomino", Aliases: []string{"rpentomino"}, Category: CategoryMethuselah, Lifespan: 1103,
+			Dims: Dimensions{Width: 3, Height: 3}, Discoverer: "John Conway", Fill: RPentomino, single: Dimensions{Width: 3, Height: 3}},
+		{Name: "acorn", Aliases: []string{"acorns"}, Category: CategoryMethuselah, Lifespan: 5206,
+			Dims: Dimensions{Width: 7, Height: 3}, Discoverer: "Charles Corderman", Fill: Acorn, single: Dimensions{Width: 7, Height: 3}},
+		{Name: "diehard", Aliases: []string{"diehards"}, Category: CategoryMethuselah, Lifespan: 130,
+			Dims: Dimensions{Width: 8, Height: 3}, Fill: Diehard, single: Dimensions{Width: 8, Height: 3}},
+	}
+)
+
+// Catalog returns every pattern in the catalog
+func Catalog() []CatalogPattern {
+	catalogMutex.RLock()
+	defer catalogMutex.RUnlock()
+
+	patterns := make([]CatalogPattern, len(catalog))
+	for i, pattern := range catalog {
+		patterns[i] = *pattern
+	}
+	return patterns
+}
+
+// LookupPattern finds the pattern in the catalog with the given name or alias, ignoring case
+func LookupPattern(name string) (CatalogPattern, bool) {
+	catalogMutex.RLock()
+	defer catalogMutex.RUnlock()
+
+	for _, pattern := range catalog {
+		for _, patternName := range pattern.names() {
+			if strings.EqualFold(patternName, name) {
+				return *pattern, true
+			}
+		}
+	}
+	return CatalogPattern{}, false
+}
+
+// RegisterPattern adds a pattern to the catalog. The pattern's Fill function must generate
+// a single copy of the pattern on a board the size of the pattern's Dims.
+func RegisterPattern(pattern CatalogPattern) error {
+	if len(pattern.Name) == 0 {
+		return errors.New("pattern must have a name")
+	}
+	if pattern.Fill == nil {
+		return errors.New("pattern must have a fill function")
+	}
+
+	catalogMutex.Lock()
+	defer catalogMutex.Unlock()
+
+	for _, existing := range catalog {
+		for _, existingName := range existing.names() {
+			for _, name := range pattern.names() {
+				if strings.EqualFold(existingName, name) {
+					return errors.New("pattern " + name + " is already in the catalog")
+				}
+			}
+		}
+	}
+
+	if pattern.single == (Dimensions{}) {
+		pattern.single = pattern.Dims
+	}
+	catalog = append(catalog, &pattern)
+
+	return nil
+}
+
+// vim: set foldmethod=marker:
@@ -0,0 +1,179 @@
+package life
+
+import "testing"
+
+func TestPatternCategoryString(t *testing.T) {
+	for c := CategoryStillLife; c <= CategoryMethuselah; c++ {
+		if c.String() == "Unknown" {
+			t.Errorf("Category %d does not have a name\n", c)
+		}
+	}
+	if patternCategory(100).String() != "Unknown" {
+		t.Error("Invalid category has a name")
+	}
+}
+
+func TestCatalogMetadata(t *testing.T) {
+	for _, pattern := range Catalog() {
+		switch pattern.Category {
+		case CategoryGun, CategoryPuffer, CategoryMethuselah:
+			continue
+		}
+
+		p, err := newPond(Dimensions{}, newTracker(), NeighborsAll, TopologyUnbounded)
+		if err != nil {
+			t.Fatalf("Unable to create pond: %s\n", err)
+		}
+		p.SetOrganisms(pattern.Living())
+
+		detector := newStabilityDetector()
+		smallest := Dimensions{}
+		for num := 0; detector.observe(num, p.Living()).State == StabilityEvolving; num++ {
+			if _, dims := BoundingBox(p.Living()); smallest.Capacity() == 0 || dims.Capacity() < smallest.Capacity() {
+				smallest = dims
+			}
+			if num > 100 {
+				t.Fatalf("Pattern %s did not repeat\n", pattern.Name)
+			}
+			SimultaneousProcessor.Process(p, ConwayTester())
+		}
+
+		stability := detector.stability
+		if stability.Start != 0 || stability.Period != pattern.Period {
+			t.Fatalf("Pattern %s settled into %s instead of repeating every %d generations\n", pattern.Name, stability, pattern.Period)
+		}
+		if (stability.State == StabilitySpaceship) != (pattern.Category == CategorySpaceship) {
+			t.Fatalf("Pattern %s is a %s but settled into %s\n", pattern.Name, pattern.Category, stability)
+		}
+		if !smallest.Equals(&pattern.Dims) {
+			t.Fatalf("Pattern %s has a smallest bounding box of %s instead of %s\n", pattern.Name, smallest.String(), pattern.Dims.String())
+		}
+	}
+}
+
+// evolvePattern returns the living organisms of the pattern after the given number of generations of Conway's rules
+func evolvePattern(pattern CatalogPattern, generations int) []Location {
+	return newHashlife(newBitboardRules(ConwayTester()), NeighborsAll).advance(pattern.Living(), generations)
+}
+
+func TestCatalogGuns(t *testing.T) {
+	for _, pattern := range Catalog() {
+		if pattern.Category != CategoryGun {
+			continue
+		}
+
+		// Every period the gun returns to its first phase and adds a glider
+		population := len(pattern.Living())
+		for i := 1; i <= 4; i++ {
+			if actual := len(evolvePattern(pattern, pattern.Period*i)); actual != population+(5*i) {
+				t.Fatalf("Gun %s has %d organisms after %d periods instead of %d\n", pattern.Name, actual, i, population+(5*i))
+			}
+		}
+	}
+}
+
+func TestCatalogPuffers(t *testing.T) {
+	for _, pattern := range Catalog() {
+		if pattern.Category != CategoryPuffer {
+			continue
+		}
+
+		// Once it has settled down, the front of the puffer repeats every period while moving at c/2
+		const front = 45
+		settled := pattern.Period
+		before := evolvePattern(pattern, settled)
+		after := evolvePattern(pattern, settled+pattern.Period)
+
+		frontOf := func(living []Location) []Location {
+			origin, dims := BoundingBox(living)
+			return Crop(living, Location{X: origin.X + dims.Width - front, Y: origin.Y}, Dimensions{Width: front, Height: dims.Height})
+		}
+		testLocationsMatch(t, Translate(frontOf(before), Location{X: pattern.Period / 2}), frontOf(after))
+
+		if len(after) <= len(before) {
+			t.Fatalf("Puffer %s did not leave any debris\n", pattern.Name)
+		}
+	}
+}
+
+func TestCatalogMethuselahs(t *testing.T) {
+	final := map[string]int{"r-pentomino": 116, "acorn": 633, "diehard": 0}
+
+	for _, pattern := range Catalog() {
+		if pattern.Category != CategoryMethuselah {
+			continue
+		}
+
+		population, known := final[pattern.Name]
+		if !known {
+			t.Fatalf("Methuselah %s does not have a known final population\n", pattern.Name)
+		}
+
+		// Whatever is left is still lifes, blinkers and gliders, none of which change the population
+		if actual := len(evolvePattern(pattern, pattern.Lifespan-1)); actual == population {
+			t.Fatalf("Methuselah %s stabilized before generation %d\n", pattern.Name, pattern.Lifespan)
+		}
+		for _, num := range []int{pattern.Lifespan, pattern.Lifespan + 1, pattern.Lifespan + 100} {
+			if actual := len(evolvePattern(pattern, num)); actual != population {
+				t.Fatalf("Methuselah %s has %d organisms at generation %d instead of %d\n", pattern.Name, actual, num, population)
+			}
+		}
+	}
+}
+
+func TestLookupPattern(t *testing.T) {
+	for _, name := range []string{"glider", "Gliders", "BLINKER", "hive"} {
+		if _, found := LookupPattern(name); !found {
+			t.Fatalf("Did not find pattern %q\n", name)
+		}
+	}
+
+	if pattern, _ := LookupPattern("Toads"); pattern.Name != "toad" {
+		t.Fatalf("Alias found pattern %q instead of toad\n", pattern.Name)
+	}
+
+	if _, found := LookupPattern("spaceship"); found {
+		t.Fatal("Found a pattern which is not in the catalog")
+	}
+}
+
+func TestRegisterPattern(t *testing.T) {
+	defer func(original []*CatalogPattern) {
+		catalogMutex.Lock()
+		catalog = original
+		catalogMutex.Unlock()
+	}(catalog)
+
+	domino := func(dimensions Dimensions, offset Location) []Location {
+		return []Location{offset, {X: offset.X + 1, Y: offset.Y}}
+	}
+
+	if err := RegisterPattern(CatalogPattern{Name: "Domino", Aliases: []string{"dominoes"}, Dims: Dimensions{Width: 2, Height: 1}, Fill: domino}); err != nil {
+		t.Fatalf("Unable to register pattern: %s\n", err)
+	}
+
+	pattern, found := LookupPattern("dominoes")
+	if !found {
+		t.Fatal("Did not find registered pattern")
+	}
+	testLocationsMatch(t, []Location{{X: 0, Y: 0}, {X: 1, Y: 0}}, pattern.Living())
+
+	if err := RegisterPattern(CatalogPattern{Name: "pair", Aliases: []string{"domino"}, Fill: domino}); err == nil {
+		t.Fatal("Registered a pattern with a name which is already in use")
+	}
+	if err := RegisterPattern(CatalogPattern{Fill: domino}); err == nil {
+		t.Fatal("Registered a pattern without a name")
+	}
+	if err := RegisterPattern(CatalogPattern{Name: "nothing"}); err == nil {
+		t.Fatal("Registered a pattern without a fill function")
+	}
+}
+
+func TestCatalogPatternInitializer(t *testing.T) {
+	pattern, _ := LookupPattern("glider")
+
+	expected := Translate(pattern.Living(), Location{X: 10, Y: 20})
+	testLocationsMatch(t, expected, pattern.Initializer()(Dimensions{Width: 40, Height: 40}, Location{X: 10, Y: 20}))
+}
+
+// vim: set foldmethod=marker:
@@ -0,0 +1,126 @@
+package life
+
+import (
+	"bytes"
+	"strconv"
+	"sync"
+)
+
+// Name given by a census to objects which are not in the catalog
+const censusUnknown = "unknown"
+
+// SplitObjects separates the living organisms into objects, each made up of the organisms which can be reached
+// from one another in steps of up to the given distance. A distance of 1 only joins organisms which touch, while
+// larger distances also join the separate parts of pseudo-objects.
+func SplitObjects(living []Location, distance int) [][]Location {
+	unvisited := make(map[Location]bool, len(living))
+	for _, loc := range living {
+		unvisited[loc] = true
+	}
+
+	objects := make([][]Location, 0)
+	for _, start := range living {
+		if !unvisited[start] {
+			continue
+		}
+		delete(unvisited, start)
+
+		object := []Location{start}
+		for i := 0; i < len(object); i++ {
+			for y := object[i].Y - distance; y <= object[i].Y+distance; y++ {
+				for x := object[i].X - distance; x <= object[i].X+distance; x++ {
+					if loc := (Location{X: x, Y: y}); unvisited[loc] {
+						delete(unvisited, loc)
+						object = append(object, loc)
+					}
+				}
+			}
+		}
+		objects = append(objects, object)
+	}
+
+	return objects
+}
+
+// canonicalKey describes the shape of the locations the same way regardless of where they are or how they are oriented
+func canonicalKey(living []Location) string {
+	var buf bytes.Buffer
+	for _, loc := range Canonical(living) {
+		buf.WriteString(strconv.Itoa(loc.X))
+		buf.WriteString(",")
+		buf.WriteString(strconv.Itoa(loc.Y))
+		buf.WriteString(";")
+	}
+	return buf.String()
+}
+
+var (
+	censusCatalog     map[string]string // The names of the canonical keys of every phase of the catalog's periodic objects
+	censusCatalogOnce sync.Once
+)
+
+// patternPhases returns the canonical keys of every phase of the given pattern under Conway's rules
+func patternPhases(pattern []Location) []string {
+	living := newTracker()
+	defer living.stop()
+
+	p, err := newPond(Dimensions{}, living, NeighborsAll, TopologyUnbounded)
+	if err != nil {
+		return nil
+	}
+	p.SetOrganisms(pattern)
+
+	phases := []string{canonicalKey(pattern)}
+	seen := map[string]bool{phases[0]: true}
+	for {
+		if err := SimultaneousProcessor.Process(p, ConwayTester()); err != nil {
+			break
+		}
+		key := canonicalKey(p.Living())
+		if seen[key] {
+			break
+		}
+		seen[key] = true
+		phases = append(phases, key)
+	}
+
+	return phases
+}
+
+func getCensusCatalog() map[string]string {
+	censusCatalogOnce.Do(func() {
+		censusCatalog = make(map[string]string)
+		for _, pattern := range Catalog() {
+			switch pattern.Category {
+			case CategoryStillLife, CategoryOscillator, CategorySpaceship:
+				for _, key := range patternPhases(pattern.Living()) {
+					censusCatalog[key] = pattern.Name
+				}
+			}
+		}
+	})
+	return censusCatalog
+}
+
+// IdentifyObject returns the name of the object, in any phase, position and orientation, if it is in the catalog.
+// Only the still lifes, oscillators and spaceships of the catalog are recognized.
+func IdentifyObject(object []Location) (string, bool) {
+	name, keyExists := getCensusCatalog()[canonicalKey(object)]
+	return name, keyExists
+}
+
+// Census splits the living organisms into objects, using the given connectivity distance,
+// and counts how many there are of each. Objects which are not in the catalog are counted as "unknown".
+func Census(living []Location, distance int) map[string]int {
+	counts := make(map[string]int)
+	for _, object := range SplitObjects(living, distance) {
+		if name, found := IdentifyObject(object); found {
+			counts[name]++
+		} else {
+			counts[censusUnknown]++
+		}
+	}
+	return counts
+}
+
+// vim: set foldmethod=marker:
@@ -0,0 +1,105 @@
+package life
+
+import "testing"
+
+func TestSplitObjects(t *testing.T) {
+	// Two blocks which are two cells apart
+	living := []Location{
+		{X: 0, Y: 0}, {X: 1, Y: 0}, {X: 0, Y: 1}, {X: 1, Y: 1},
+		{X: 3, Y: 0}, {X: 4, Y: 0}, {X: 3, Y: 1}, {X: 4, Y: 1},
+	}
+
+	if objects := SplitObjects(living, 1); len(objects) != 2 {
+		t.Fatalf("Found %d objects instead of 2\n", len(objects))
+	}
+
+	objects := SplitObjects(living, 2)
+	if len(objects) != 1 {
+		t.Fatalf("Found %d objects instead of 1\n", len(objects))
+	}
+	testLocationsMatch(t, living, objects[0])
+
+	if objects := SplitObjects([]Location{}, 1); len(objects) != 0 {
+		t.Fatalf("Found %d objects instead of none\n", len(objects))
+	}
+}
+
+func TestCanonicalKey(t *testing.T) {
+	// An L-shape in two different orientations and positions
+	shape := []Location{{X: 0, Y: 0}, {X: 0, Y: 1}, {X: 0, Y: 2}, {X: 1, Y: 2}}
+	rotated := []Location{{X: 10, Y: -5}, {X: 11, Y: -5}, {X: 12, Y: -5}, {X: 10, Y: -4}}
+
+	if canonicalKey(shape) != canonicalKey(rotated) {
+		t.Fatal("Rotated shape does not have the same canonical key")
+	}
+
+	line := []Location{{X: 0, Y: 0}, {X: 0, Y: 1}, {X: 0, Y: 2}, {X: 0, Y: 3}}
+	if canonicalKey(shape) == canonicalKey(line) {
+		t.Fatal("Different shapes have the same canonical key")
+	}
+}
+
+func TestCensusPatterns(t *testing.T) {
+	populations := map[string]int{"block": 4, "beehive": 6, "loaf": 7, "boat": 5, "blinker": 3,
+		"toad": 6, "beacon": 6, "pulsar": 48, "glider": 5}
+
+	for name, population := range populations {
+		entry, found := LookupPattern(name)
+		if !found {
+			t.Fatalf("Pattern %s is not in the catalog\n", name)
+		}
+
+		pattern := entry.Living()
+		if len(pattern) != population {
+			t.Fatalf("Pattern %s has %d organisms instead of %d\n", name, len(pattern), population)
+		}
+
+		if identified, found := IdentifyObject(pattern); !found || identified != name {
+			t.Fatalf("Pattern %s was identified as %q\n", name, identified)
+		}
+	}
+}
+
+func TestIdentifyObjectPhases(t *testing.T) {
+	p, err := newPond(Dimensions{}, newTracker(), NeighborsAll, TopologyUnbounded)
+	if err != nil {
+		t.Fatalf("Unable to create pond: %s\n", err)
+	}
+
+	// A glider flying in another direction
+	p.SetOrganisms([]Location{{X: 1, Y: 0}, {X: 0, Y: 1}, {X: 0, Y: 2}, {X: 1, Y: 2}, {X: 2, Y: 2}})
+	for i := 0; i < 8; i++ {
+		if name, found := IdentifyObject(p.Living()); !found || name != "glider" {
+			t.Fatalf("Glider at generation %d was identified as %q\n", i, name)
+		}
+		SimultaneousProcessor.Process(p, ConwayTester())
+	}
+
+	if _, found := IdentifyObject([]Location{{X: 0, Y: 0}}); found {
+		t.Fatal("Identified a single organism")
+	}
+}
+
+func TestCensus(t *testing.T) {
+	living := make([]Location, 0)
+	living = append(living, Blocks(Dimensions{Width: 5, Height: 5}, Location{X: 0, Y: 0})...)
+	living = append(living, Blocks(Dimensions{Width: 5, Height: 5}, Location{X: 10, Y: 0})...)
+	living = append(living, Blinkers(Dimensions{Width: 4, Height: 4}, Location{X: 20, Y: 0})...)
+	living = append(living, Gliders(Dimensions{Width: 4, Height: 4}, Location{X: 0, Y: 10})...)
+	living = append(living, Beacons(Dimensions{Width: 5, Height: 5}, Location{X: 10, Y: 10})...)
+	living = append(living, Location{X: 30, Y: 30})
+
+	expected := map[string]int{"block": 2, "blinker": 1, "glider": 1, "beacon": 1, "unknown": 1}
+	actual := Census(living, 2)
+
+	if len(actual) != len(expected) {
+		t.Fatalf("Census found %v instead of %v\n", actual, expected)
+	}
+	for name, count := range expected {
+		if actual[name] != count {
+			t.Fatalf("Census found %v instead of %v\n", actual, expected)
+		}
+	}
+}
+
+// vim: set foldmethod=marker:
@@ -0,0 +1,129 @@
+package life
+
+import (
+	"fmt"
+)
+
+type orientation int
+
+// Enumeration of the ways a pattern can be oriented when it is placed by a Composer
+const (
+	OrientationNone orientation = iota
+	OrientationRotate90
+	OrientationRotate180
+	OrientationRotate270
+	OrientationFlipHorizontal
+	OrientationFlipVertical
+	OrientationFlipDiagonal
+	OrientationFlipAntiDiagonal
+)
+
+func (t orientation) String() string {
+	switch t {
+	case OrientationNone:
+		return "None"
+	case OrientationRotate90:
+		return "Rotate90"
+	case OrientationRotate180:
+		return "Rotate180"
+	case OrientationRotate270:
+		return "Rotate270"
+	case OrientationFlipHorizontal:
+		return "FlipHorizontal"
+	case OrientationFlipVertical:
+		return "FlipVertical"
+	case OrientationFlipDiagonal:
+		return "FlipDiagonal"
+	case OrientationFlipAntiDiagonal:
+		return "FlipAntiDiagonal"
+	}
+	return "Unknown"
+}
+
+// apply orients the locations, keeping the top-left corner of their bounding box in place
+func (t orientation) apply(living []Location) []Location {
+	switch t {
+	case OrientationRotate90:
+		return Rotate90(living)
+	case OrientationRotate180:
+		return Rotate180(living)
+	case OrientationRotate270:
+		return Rotate270(living)
+	case OrientationFlipHorizontal:
+		return FlipHorizontal(living)
+	case OrientationFlipVertical:
+		return FlipVertical(living)
+	case OrientationFlipDiagonal:
+		return FlipDiagonal(living)
+	case OrientationFlipAntiDiagonal:
+		return FlipAntiDiagonal(living)
+	}
+	return living
+}
+
+// Composer builds a board out of patterns, making sure that none of them overlap or come too close to each other
+type Composer struct {
+	margin int
+	owners map[Location]string // The name of the pattern which placed each organism
+	living []Location
+}
+
+// Place adds the pattern to the board. The pattern is oriented and then moved so that the top-left corner of its
+// bounding box is at the given offset. It is not placed if any of its organisms would be within the composer's
+// margin of an organism of a pattern which was already placed.
+func (t *Composer) Place(name string, pattern []Location, offset Location, orient orientation) error {
+	oriented := Normalize(orient.apply(pattern))
+	placed := Translate(oriented, offset)
+
+	for _, loc := range placed {
+		if owner, keyExists := t.owners[loc]; keyExists {
+			return fmt.Errorf("pattern %q overlaps pattern %q at %s", name, owner, loc.String())
+		}
+	}
+	for _, loc := range placed {
+		for y := loc.Y - t.margin; y <= loc.Y+t.margin; y++ {
+			for x := loc.X - t.margin; x <= loc.X+t.margin; x++ {
+				if owner, keyExists := t.owners[Location{X: x, Y: y}]; keyExists {
+					return fmt.Errorf("pattern %q at %s is within %d of pattern %q", name, loc.String(), t.margin, owner)
+				}
+			}
+		}
+	}
+
+	// Organisms of the same pattern can repeat
+	for _, loc := range placed {
+		if _, keyExists := t.owners[loc]; !keyExists {
+			t.owners[loc] = name
+			t.living = append(t.living, loc)
+		}
+	}
+
+	return nil
+}
+
+// Living returns the organisms of all of the patterns which have been placed
+func (t *Composer) Living() []Location {
+	return append([]Location{}, t.living...)
+}
+
+// Initializer returns a function which can be given to New to seed a Life with the composed board
+func (t *Composer) Initializer() func(Dimensions, Location) []Location {
+	living := t.Living()
+	return func(dimensions Dimensions, offset Location) []Location {
+		return Translate(living, offset)
+	}
+}
+
+// NewComposer creates a composer which requires the patterns to be further apart than the given margin.
+// A margin of zero only prevents patterns from overlapping, while a margin of one also prevents them from touching.
+func NewComposer(margin int) *Composer {
+	t := new(Composer)
+
+	t.margin = margin
+	t.owners = make(map[Location]string)
+	t.living = make([]Location, 0)
+
+	return t
+}
+
+// vim: set foldmethod=marker:
@@ -0,0 +1,91 @@
+package life
+
+import "testing"
+
+func TestOrientationString(t *testing.T) {
+	for o := OrientationNone; o <= OrientationFlipAntiDiagonal; o++ {
+		if o.String() == "Unknown" {
+			t.Errorf("Orientation %d does not have a name\n", o)
+		}
+	}
+	if orientation(100).String() != "Unknown" {
+		t.Error("Invalid orientation has a name")
+	}
+}
+
+func TestComposerPlace(t *testing.T) {
+	glider := Gliders(Dimensions{Width: 4, Height: 4}, Location{X: 50, Y: 50})
+
+	composer := NewComposer(1)
+	if err := composer.Place("southeast", glider, Location{X: 0, Y: 0}, OrientationNone); err != nil {
+		t.Fatalf("Unable to place glider: %s\n", err)
+	}
+	if err := composer.Place("northwest", glider, Location{X: 10, Y: 10}, OrientationRotate180); err != nil {
+		t.Fatalf("Unable to place glider: %s\n", err)
+	}
+
+	expected := []Location{
+		{X: 1, Y: 0}, {X: 2, Y: 1}, {X: 0, Y: 2}, {X: 1, Y: 2}, {X: 2, Y: 2},
+		{X: 10, Y: 10}, {X: 11, Y: 10}, {X: 12, Y: 10}, {X: 10, Y: 11}, {X: 11, Y: 12},
+	}
+	testLocationsMatch(t, expected, composer.Living())
+}
+
+func TestComposerCollisions(t *testing.T) {
+	block := Blocks(Dimensions{Width: 5, Height: 5}, Location{})
+
+	composer := NewComposer(1)
+	if err := composer.Place("first", block, Location{X: 0, Y: 0}, OrientationNone); err != nil {
+		t.Fatalf("Unable to place block: %s\n", err)
+	}
+
+	// Overlapping
+	if err := composer.Place("overlapping", block, Location{X: 1, Y: 1}, OrientationNone); err == nil {
+		t.Error("Placed a block which overlaps another")
+	}
+	// Touching
+	if err := composer.Place("touching", block, Location{X: 2, Y: 0}, OrientationNone); err == nil {
+		t.Error("Placed a block which touches another")
+	}
+	// Just outside of the margin
+	if err := composer.Place("separate", block, Location{X: 3, Y: 0}, OrientationNone); err != nil {
+		t.Errorf("Unable to place block: %s\n", err)
+	}
+
+	if len(composer.Living()) != 8 {
+		t.Fatalf("Composer has %d organisms instead of 8\n", len(composer.Living()))
+	}
+
+	// Without a margin, only overlapping is prevented
+	composer = NewComposer(0)
+	composer.Place("first", block, Location{X: 0, Y: 0}, OrientationNone)
+	if err := composer.Place("touching", block, Location{X: 2, Y: 0}, OrientationNone); err != nil {
+		t.Errorf("Unable to place block: %s\n", err)
+	}
+}
+
+func TestComposerInitializer(t *testing.T) {
+	glider := Gliders(Dimensions{Width: 4, Height: 4}, Location{})
+
+	// Two gliders headed toward each other
+	composer := NewComposer(2)
+	if err := composer.Place("southeast", glider, Location{X: 0, Y: 0}, OrientationNone); err != nil {
+		t.Fatalf("Unable to place glider: %s\n", err)
+	}
+	if err := composer.Place("northwest", glider, Location{X: 6, Y: 6}, OrientationRotate180); err != nil {
+		t.Fatalf("Unable to place glider: %s\n", err)
+	}
+
+	life, err := New(Dimensions{}, NeighborsAll, TopologyUnbounded, composer.Initializer(), ConwayTester(), SimultaneousProcessor)
+	if err != nil {
+		t.Fatalf("Unable to create strategy: %s\n", err)
+	}
+	testLocationsMatch(t, composer.Living(), life.Seed)
+
+	// Two gliders which collide head on like this leave nothing behind
+	if gen := life.Generation(30); len(gen.Living) != 0 {
+		t.Fatalf("Found %d living organisms instead of none\n", len(gen.Living))
+	}
+}
+
+// vim: set foldmethod=marker:
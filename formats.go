@@ -0,0 +1,110 @@
+package life
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"io/ioutil"
+	"strings"
+)
+
+type patternFormat int
+
+// Enumeration of the supported pattern file formats
+const (
+	formatRLE patternFormat = iota
+	formatPlaintext
+	formatLife105
+	formatLife106
+	formatMacrocell
+)
+
+func (t patternFormat) String() string {
+	switch t {
+	case formatRLE:
+		return "RLE"
+	case formatPlaintext:
+		return "Plaintext"
+	case formatLife105:
+		return "Life 1.05"
+	case formatLife106:
+		return "Life 1.06"
+	case formatMacrocell:
+		return "Macrocell"
+	}
+	return "Unknown"
+}
+
+// detectPatternFormat determines the format of a pattern file from its contents
+func detectPatternFormat(contents []byte) patternFormat {
+	scanner := bufio.NewScanner(bytes.NewReader(contents))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+
+		switch {
+		case len(line) == 0:
+			continue
+		case strings.HasPrefix(line, life106Header):
+			return formatLife106
+		case strings.HasPrefix(line, life105Header):
+			return formatLife105
+		case strings.HasPrefix(line, macrocellHeader):
+			return formatMacrocell
+		case strings.HasPrefix(line, "!"):
+			return formatPlaintext
+		case strings.HasPrefix(line, "#"):
+			// Most likely the comments of an RLE file
+			continue
+		case strings.Trim(line, ".O*") == "":
+			return formatPlaintext
+		}
+
+		break
+	}
+
+	return formatRLE
+}
+
+// ReadPattern decodes a pattern from any of the supported formats,
+// which are detected from the contents:
+// RLE, plaintext (.cells), Life 1.05, Life 1.06 and macrocell
+func ReadPattern(r io.Reader) (*PatternFile, error) {
+	contents, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	switch detectPatternFormat(contents) {
+	case formatPlaintext:
+		return ReadPlaintext(bytes.NewReader(contents))
+	case formatLife105:
+		return ReadLife105(bytes.NewReader(contents))
+	case formatLife106:
+		return ReadLife106(bytes.NewReader(contents))
+	case formatMacrocell:
+		return ReadMacrocell(bytes.NewReader(contents))
+	}
+
+	return ReadRLE(bytes.NewReader(contents))
+}
+
+// PatternFile captures a pattern along with the metadata that pattern files carry
+type PatternFile struct {
+	Name     string
+	Author   string
+	Comments []string
+	Rules    *Rules     // Can be nil if the file did not specify any rules
+	Dims     Dimensions // The size of the pattern
+	Living   []Location
+}
+
+// Initializer returns a function which can be given to New to seed a Life with the pattern.
+// Formats such as Life 1.05 and 1.06 center their patterns on the origin, so the top-left corner
+// of the pattern is moved to the given offset, as with the patterns of this package.
+func (t *PatternFile) Initializer() func(Dimensions, Location) []Location {
+	return func(dimensions Dimensions, offset Location) []Location {
+		return Translate(Normalize(t.Living), offset)
+	}
+}
+
+// vim: set foldmethod=marker:
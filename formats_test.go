@@ -0,0 +1,61 @@
+package life
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestReadPattern(t *testing.T) {
+	expected := Gliders(Dimensions{Width: 3, Height: 3}, Location{})
+
+	writers := []struct {
+		format patternFormat
+		write  func(*bytes.Buffer, *PatternFile) error
+	}{
+		{formatRLE, func(buf *bytes.Buffer, p *PatternFile) error { return WriteRLE(buf, p) }},
+		{formatPlaintext, func(buf *bytes.Buffer, p *PatternFile) error { return WritePlaintext(buf, p) }},
+		{formatLife105, func(buf *bytes.Buffer, p *PatternFile) error { return WriteLife105(buf, p) }},
+		{formatLife106, func(buf *bytes.Buffer, p *PatternFile) error { return WriteLife106(buf, p) }},
+		{formatMacrocell, func(buf *bytes.Buffer, p *PatternFile) error { return WriteMacrocell(buf, p) }},
+	}
+
+	for _, writer := range writers {
+		var buf bytes.Buffer
+		if err := writer.write(&buf, &PatternFile{Name: "Glider", Living: expected}); err != nil {
+			t.Fatalf("Unable to write %s: %s\n", writer.format.String(), err)
+		}
+
+		if format := detectPatternFormat(buf.Bytes()); format != writer.format {
+			t.Errorf("Detected %s instead of %s\n", format.String(), writer.format.String())
+		}
+
+		pattern, err := ReadPattern(&buf)
+		if err != nil {
+			t.Fatalf("Unable to read %s: %s\n", writer.format.String(), err)
+		}
+
+		testLocationsMatch(t, expected, pattern.Living)
+	}
+}
+
+func TestDetectPatternFormatHeaderless(t *testing.T) {
+	if format := detectPatternFormat([]byte(".O.\n..O\nOOO\n")); format != formatPlaintext {
+		t.Errorf("Detected %s instead of plaintext\n", format.String())
+	}
+
+	if format := detectPatternFormat([]byte("bo$2bo$3o!")); format != formatRLE {
+		t.Errorf("Detected %s instead of RLE\n", format.String())
+	}
+}
+
+func TestPatternFileInitializer(t *testing.T) {
+	pattern := &PatternFile{Living: Blinkers(Dimensions{Width: 3, Height: 3}, Location{})}
+
+	offset := Location{X: 2, Y: 2}
+	actual := pattern.Initializer()(Dimensions{Width: 6, Height: 6}, offset)
+
+	// The top-left corner of the pattern, rather than of its board, is moved to the offset
+	testLocationsMatch(t, []Location{{X: 2, Y: 2}, {X: 3, Y: 2}, {X: 4, Y: 2}}, actual)
+}
+
+// vim: set foldmethod=marker:
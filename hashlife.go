@@ -0,0 +1,218 @@
+package life
+
+// Once this many results are remembered, they are all forgotten to keep memory in check
+const hashlifeMaxResults = 1 << 22
+
+// Once the quadtree holds this many nodes, it is rebuilt along with the results before the next advance
+const hashlifeMaxNodes = 1 << 22
+
+type hashlifeKey struct {
+	node *quadNode
+	step uint // The node was advanced by 2^step generations
+}
+
+// hashlife advances quadtrees of organisms by memoizing the future of every node it encounters.
+// Since identical nodes are shared, patterns with any repetition in space or time are computed
+// in far fewer steps than there are generations.
+type hashlife struct {
+	tree          *quadtree
+	rules         *bitboardRules
+	neighborhoods *neighborhoodTable // Replaces the rules when they are given the configuration of the neighbors
+	selector      neighborsSelector
+	results       map[hashlifeKey]*quadNode
+	maxNodes      int
+}
+
+// leafCell returns the state of the given cell of a level 2 node
+func leafCell(n *quadNode, x, y int) bool {
+	quadrant := n.nw
+	switch {
+	case x >= 2 && y >= 2:
+		quadrant = n.se
+	case x >= 2:
+		quadrant = n.ne
+	case y >= 2:
+		quadrant = n.sw
+	}
+
+	x, y = x%2, y%2
+	cell := quadrant.nw
+	switch {
+	case x == 1 && y == 1:
+		cell = quadrant.se
+	case x == 1:
+		cell = quadrant.ne
+	case y == 1:
+		cell = quadrant.sw
+	}
+
+	return cell.population > 0
+}
+
+// leafStep returns the center of the given level 2 node a single generation later
+func (t *hashlife) leafStep(n *quadNode) *quadNode {
+	center := make([]*quadNode, 4)
+	for i, loc := range []Location{{X: 1, Y: 1}, {X: 2, Y: 1}, {X: 1, Y: 2}, {X: 2, Y: 2}} {
+		var neighborhood Neighborhood
+		for j, offset := range neighborhoodOffsets {
+			direction := Neighborhood(1 << uint(j))
+			if t.selector.selects(direction) && leafCell(n, loc.X+offset.X, loc.Y+offset.Y) {
+				neighborhood |= direction
+			}
+		}
+
+		alive := leafCell(n, loc.X, loc.Y)
+		switch {
+		case t.neighborhoods != nil && alive:
+			center[i] = t.tree.leaf(t.neighborhoods.survive[neighborhood])
+		case t.neighborhoods != nil:
+			center[i] = t.tree.leaf(t.neighborhoods.born[neighborhood])
+		case alive:
+			center[i] = t.tree.leaf(t.rules.survive[neighborhood.Count()])
+		default:
+			center[i] = t.tree.leaf(t.rules.born[neighborhood.Count()])
+		}
+	}
+
+	return t.tree.node(center[0], center[1], center[2], center[3])
+}
+
+// centered returns the node one level down which is at the center of the given node
+func (t *hashlife) centered(n *quadNode) *quadNode {
+	return t.tree.node(n.nw.se, n.ne.sw, n.sw.ne, n.se.nw)
+}
+
+// subnodes returns the nine overlapping nodes one level down which tile the given node
+func (t *hashlife) subnodes(n *quadNode) [9]*quadNode {
+	return [9]*quadNode{
+		n.nw,
+		t.tree.node(n.nw.ne, n.ne.nw, n.nw.se, n.ne.sw),
+		n.ne,
+		t.tree.node(n.nw.sw, n.nw.se, n.sw.nw, n.sw.ne),
+		t.tree.node(n.nw.se, n.ne.sw, n.sw.ne, n.se.nw),
+		t.tree.node(n.ne.sw, n.ne.se, n.se.nw, n.se.ne),
+		n.sw,
+		t.tree.node(n.sw.ne, n.se.nw, n.sw.se, n.se.sw),
+		n.se,
+	}
+}
+
+// result returns the center of the given node after 2^step generations, where the step is at most the node's level - 2
+func (t *hashlife) result(n *quadNode, step uint) *quadNode {
+	if n.population == 0 {
+		return t.tree.emptyNode(n.level - 1)
+	}
+
+	key := hashlifeKey{node: n, step: step}
+	if r, keyExists := t.results[key]; keyExists {
+		return r
+	}
+
+	var r *quadNode
+	sub := t.subnodes(n)
+	switch {
+	case n.level == 2:
+		r = t.leafStep(n)
+	case step == n.level-2:
+		// Advance each of the nine subnodes halfway, then their combinations the rest of the way
+		var halfway [9]*quadNode
+		for i, s := range sub {
+			halfway[i] = t.result(s, step-1)
+		}
+		r = t.tree.node(
+			t.result(t.tree.node(halfway[0], halfway[1], halfway[3], halfway[4]), step-1),
+			t.result(t.tree.node(halfway[1], halfway[2], halfway[4], halfway[5]), step-1),
+			t.result(t.tree.node(halfway[3], halfway[4], halfway[6], halfway[7]), step-1),
+			t.result(t.tree.node(halfway[4], halfway[5], halfway[7], halfway[8]), step-1))
+	default:
+		// The step is small enough that only the combinations of the subnodes' centers need to be advanced
+		var centers [9]*quadNode
+		for i, s := range sub {
+			centers[i] = t.centered(s)
+		}
+		r = t.tree.node(
+			t.result(t.tree.node(centers[0], centers[1], centers[3], centers[4]), step),
+			t.result(t.tree.node(centers[1], centers[2], centers[4], centers[5]), step),
+			t.result(t.tree.node(centers[3], centers[4], centers[6], centers[7]), step),
+			t.result(t.tree.node(centers[4], centers[5], centers[7], centers[8]), step))
+	}
+
+	if len(t.results) >= hashlifeMaxResults {
+		t.results = make(map[hashlifeKey]*quadNode)
+	}
+	t.results[key] = r
+
+	return r
+}
+
+// expand returns a node one level up with the given node at its center
+func (t *hashlife) expand(n *quadNode) *quadNode {
+	empty := t.tree.emptyNode(n.level - 1)
+	return t.tree.node(
+		t.tree.node(empty, empty, empty, n.nw),
+		t.tree.node(empty, empty, n.ne, empty),
+		t.tree.node(empty, n.sw, empty, empty),
+		t.tree.node(n.se, empty, empty, empty))
+}
+
+// advance returns the given living organisms after the given number of generations
+func (t *hashlife) advance(living []Location, generations int) []Location {
+	// The root is rebuilt from the living organisms every time, so nothing has to survive starting over.
+	// Otherwise the engine lives as long as its processor and the canonical nodes would never be forgotten.
+	if len(t.tree.nodes) >= t.maxNodes {
+		t.tree = newQuadtree()
+		t.results = make(map[hashlifeKey]*quadNode)
+	}
+
+	root, origin := t.tree.fromLocations(living, 3)
+
+	for step := uint(0); generations > 0; step++ {
+		if generations&1 == 1 {
+			// Make sure the organisms cannot travel past the part of the node which is returned
+			for root.level < step+2 || root.population != t.centered(root).population {
+				half := root.size() / 2
+				root = t.expand(root)
+				origin = Location{X: origin.X - half, Y: origin.Y - half}
+			}
+			half := root.size() / 2
+			root = t.expand(root)
+			origin = Location{X: origin.X - half, Y: origin.Y - half}
+
+			quarter := root.size() / 4
+			root = t.result(root, step)
+			origin = Location{X: origin.X + quarter, Y: origin.Y + quarter}
+		}
+		generations >>= 1
+	}
+
+	return t.tree.locations(root, origin)
+}
+
+// sameRules tests if the engine was created for the given rules, only one of which is set
+func (t *hashlife) sameRules(rules *bitboardRules, neighborhoods *neighborhoodTable) bool {
+	if neighborhoods != nil || t.neighborhoods != nil {
+		return neighborhoods != nil && t.neighborhoods != nil && *neighborhoods == *t.neighborhoods
+	}
+	return t.rules.survive == rules.survive && t.rules.born == rules.born
+}
+
+func newHashlife(rules *bitboardRules, selector neighborsSelector) *hashlife {
+	t := new(hashlife)
+
+	t.tree = newQuadtree()
+	t.rules = rules
+	t.selector = selector
+	t.results = make(map[hashlifeKey]*quadNode)
+	t.maxNodes = hashlifeMaxNodes
+
+	return t
+}
+
+// newNeighborhoodHashlife creates an engine whose rules are given the configuration of the neighbors
+func newNeighborhoodHashlife(neighborhoods *neighborhoodTable, selector neighborsSelector) *hashlife {
+	t := newHashlife(nil, selector)
+	t.neighborhoods = neighborhoods
+	return t
+}
+
+// vim: set foldmethod=marker:
@@ -0,0 +1,107 @@
+package life
+
+import "testing"
+
+func TestHashlifeMatchesSimultaneous(t *testing.T) {
+	selectors := []neighborsSelector{NeighborsAll, NeighborsOrthogonal, NeighborsOblique}
+	rules := []func(int, bool) bool{ConwayTester(), RulesTester(&Rules{Survive: []int{2, 3}, Born: []int{3, 6}})}
+	size := Dimensions{Width: 16, Height: 16}
+
+	for _, selector := range selectors {
+		for _, rule := range rules {
+			seed := Random(size, Location{X: -5, Y: -9}, 40)
+
+			expected, err := newPond(size, newTracker(), selector, TopologyUnbounded)
+			if err != nil {
+				t.Fatalf("Unable to create pond: %s\n", err)
+			}
+			expected.SetOrganisms(seed)
+
+			engine := newHashlife(newBitboardRules(rule), selector)
+			for gen := 1; gen <= 40; gen++ {
+				if err := SimultaneousProcessor.Process(expected, rule); err != nil {
+					t.Fatalf("Unable to process pond: %s\n", err)
+				}
+
+				actual := engine.advance(seed, gen)
+				if len(actual) != expected.living.Count() {
+					t.Fatalf("%s neighbors at generation %d has %d living organisms instead of %d\n",
+						selector.String(), gen, len(actual), expected.living.Count())
+				}
+				for _, loc := range actual {
+					if !expected.IsAlive(loc) {
+						t.Fatalf("%s neighbors at generation %d has unexpected living organism at %v\n",
+							selector.String(), gen, loc)
+					}
+				}
+			}
+		}
+	}
+}
+
+func TestHashlifeGlider(t *testing.T) {
+	seed := Gliders(Dimensions{Width: 4, Height: 4}, Location{})
+
+	// A glider travels one cell diagonally every four generations
+	generations := 1000000000
+	offset := generations / 4
+
+	engine := newHashlife(newBitboardRules(ConwayTester()), NeighborsAll)
+	actual := engine.advance(seed, generations)
+
+	expected := make([]Location, len(seed))
+	for i, loc := range seed {
+		expected[i] = Location{X: loc.X + offset, Y: loc.Y + offset}
+	}
+
+	testLocationsMatch(t, expected, actual)
+}
+
+func TestHashlifeMaxNodes(t *testing.T) {
+	size := Dimensions{Width: 24, Height: 24}
+	seed := SeededRandom(size, Location{}, 40, 21)
+
+	expected, err := newPond(size, newTracker(), NeighborsAll, TopologyUnbounded)
+	if err != nil {
+		t.Fatalf("Unable to create pond: %s\n", err)
+	}
+	expected.SetOrganisms(seed)
+
+	engine := newHashlife(newBitboardRules(ConwayTester()), NeighborsAll)
+	engine.maxNodes = 2000
+
+	living := seed
+	rebuilt := false
+	for gen := 1; gen <= 60; gen++ {
+		// The tree starts over once it reaches the limit, so it only grows past it by a single advance
+		tree, full := engine.tree, len(engine.tree.nodes) >= engine.maxNodes
+		living = engine.advance(living, 1)
+		if full {
+			rebuilt = true
+			if engine.tree == tree {
+				t.Fatalf("Generation %d kept a tree which reached the limit\n", gen)
+			}
+		}
+		if len(engine.tree.nodes) >= 2*engine.maxNodes {
+			t.Fatalf("Generation %d has %d nodes\n", gen, len(engine.tree.nodes))
+		}
+
+		if err := SimultaneousProcessor.Process(expected, ConwayTester()); err != nil {
+			t.Fatalf("Unable to process pond: %s\n", err)
+		}
+		testLocationsMatch(t, expected.Living(), living)
+	}
+
+	if !rebuilt {
+		t.Fatal("The quadtree never reached its limit")
+	}
+}
+
+func TestHashlifeEmpty(t *testing.T) {
+	engine := newHashlife(newBitboardRules(ConwayTester()), NeighborsAll)
+	if living := engine.advance([]Location{}, 12345); len(living) != 0 {
+		t.Fatalf("Found %d living organisms when there should be none\n", len(living))
+	}
+}
+
+// vim: set foldmethod=marker:
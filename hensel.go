@@ -0,0 +1,166 @@
+package life
+
+import "math/bits"
+
+// Neighborhood is the configuration of the living neighbors of an organism.
+// Each bit is set when the neighbor in that direction is alive.
+type Neighborhood uint8
+
+// The bit of each of the eight directions around an organism
+const (
+	NeighborNW Neighborhood = 1 << iota
+	NeighborN
+	NeighborNE
+	NeighborW
+	NeighborE
+	NeighborSW
+	NeighborS
+	NeighborSE
+)
+
+// The offsets of each direction, in the order of their bits
+var neighborhoodOffsets = [8]Location{
+	{X: -1, Y: -1}, {X: 0, Y: -1}, {X: 1, Y: -1},
+	{X: -1, Y: 0}, {X: 1, Y: 0},
+	{X: -1, Y: 1}, {X: 0, Y: 1}, {X: 1, Y: 1},
+}
+
+const orthogonalNeighborhood = NeighborN | NeighborW | NeighborE | NeighborS
+
+// selects tests if the neighbor in the given direction is one of the selected neighbors
+func (t neighborsSelector) selects(direction Neighborhood) bool {
+	switch t {
+	case NeighborsOrthogonal:
+		return direction&orthogonalNeighborhood != 0
+	case NeighborsOblique:
+		return direction&orthogonalNeighborhood == 0
+	}
+	return true
+}
+
+// Count returns the number of living neighbors
+func (t Neighborhood) Count() int {
+	return bits.OnesCount8(uint8(t))
+}
+
+// Letter returns the letter which names the configuration in Hensel notation, which is the same for every rotation
+// and reflection of it. Zero and eight neighbors only have one configuration, which does not have a letter.
+func (t Neighborhood) Letter() byte {
+	return henselLetters[t]
+}
+
+// transform returns the configuration with every neighbor moved by the given function of its offset
+func (t Neighborhood) transform(move func(Location) Location) Neighborhood {
+	var transformed Neighborhood
+	for i, offset := range neighborhoodOffsets {
+		if t&(1<<uint(i)) == 0 {
+			continue
+		}
+		moved := move(offset)
+		for j, other := range neighborhoodOffsets {
+			if moved == other {
+				transformed |= 1 << uint(j)
+			}
+		}
+	}
+	return transformed
+}
+
+// symmetries returns the configuration in each of its four rotations and their reflections
+func (t Neighborhood) symmetries() []Neighborhood {
+	rotate := func(loc Location) Location { return Location{X: -loc.Y, Y: loc.X} }
+	reflect := func(loc Location) Location { return Location{X: -loc.X, Y: loc.Y} }
+
+	images := make([]Neighborhood, 0, 8)
+	for _, image := range []Neighborhood{t, t.transform(reflect)} {
+		for i := 0; i < 4; i++ {
+			images = append(images, image)
+			image = image.transform(rotate)
+		}
+	}
+	return images
+}
+
+// henselOrder lists the letters of the configurations of each number of neighbors in their canonical order
+var henselOrder = [9]string{"", "ce", "ceaikn", "ceaiknjqry", "ceaiknjqrtwyz", "ceaiknjqry", "ceaikn", "ce", ""}
+
+// henselConfigurations holds one configuration of each letter of up to four neighbors.
+// The configurations of five or more neighbors take the letter of the configuration of the dead neighbors.
+var henselConfigurations = map[int]map[byte]Neighborhood{
+	1: {
+		'c': NeighborNW,
+		'e': NeighborN,
+	},
+	2: {
+		'c': NeighborNW | NeighborNE,
+		'e': NeighborN | NeighborW,
+		'a': NeighborNW | NeighborN,
+		'i': NeighborW | NeighborE,
+		'k': NeighborNW | NeighborE,
+		'n': NeighborNE | NeighborSW,
+	},
+	3: {
+		'c': NeighborNW | NeighborNE | NeighborSW,
+		'e': NeighborN | NeighborW | NeighborE,
+		'a': NeighborNW | NeighborN | NeighborW,
+		'i': NeighborNW | NeighborN | NeighborNE,
+		'k': NeighborN | NeighborE | NeighborSW,
+		'n': NeighborNW | NeighborNE | NeighborW,
+		'j': NeighborN | NeighborNE | NeighborW,
+		'q': NeighborN | NeighborNE | NeighborSW,
+		'r': NeighborNW | NeighborW | NeighborE,
+		'y': NeighborNW | NeighborE | NeighborSW,
+	},
+	4: {
+		'c': NeighborNW | NeighborNE | NeighborSW | NeighborSE,
+		'e': NeighborN | NeighborW | NeighborE | NeighborS,
+		'a': NeighborNW | NeighborN | NeighborNE | NeighborW,
+		'i': NeighborNW | NeighborNE | NeighborW | NeighborE,
+		'k': NeighborNW | NeighborN | NeighborE | NeighborSW,
+		'n': NeighborNW | NeighborN | NeighborNE | NeighborSW,
+		'j': NeighborN | NeighborW | NeighborE | NeighborSW,
+		'q': NeighborN | NeighborNE | NeighborE | NeighborSW,
+		'r': NeighborNW | NeighborN | NeighborW | NeighborE,
+		't': NeighborNW | NeighborW | NeighborE | NeighborSW,
+		'w': NeighborN | NeighborNE | NeighborW | NeighborSW,
+		'y': NeighborNW | NeighborNE | NeighborE | NeighborSW,
+		'z': NeighborNE | NeighborW | NeighborE | NeighborSW,
+	},
+}
+
+// henselLetters is the letter of every configuration
+var henselLetters [256]byte
+
+func init() {
+	for _, configurations := range henselConfigurations {
+		for letter, configuration := range configurations {
+			for _, image := range configuration.symmetries() {
+				henselLetters[image] = letter
+				if image.Count() < 4 {
+					henselLetters[^image] = letter
+				}
+			}
+		}
+	}
+}
+
+// neighborhoodTable is the outcome of the rules for every configuration of neighbors
+type neighborhoodTable struct {
+	survive [256]bool
+	born    [256]bool
+}
+
+func newNeighborhoodTable(rules func(Neighborhood, bool) bool) *neighborhoodTable {
+	table := new(neighborhoodTable)
+	for n := 0; n < 256; n++ {
+		table.survive[n] = rules(Neighborhood(n), true)
+		table.born[n] = rules(Neighborhood(n), false)
+	}
+
+	// As with the other processors, nothing can be born without any neighbors
+	table.born[0] = false
+
+	return table
+}
+
+// vim: set foldmethod=marker:
@@ -0,0 +1,96 @@
+package life
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestNeighborhoodCount(t *testing.T) {
+	if count := (NeighborNW | NeighborS | NeighborE).Count(); count != 3 {
+		t.Fatalf("Counted %d neighbors instead of 3\n", count)
+	}
+	if count := Neighborhood(255).Count(); count != 8 {
+		t.Fatalf("Counted %d neighbors instead of 8\n", count)
+	}
+}
+
+func TestNeighborhoodLetters(t *testing.T) {
+	letters := make(map[int]map[byte]bool)
+	for n := 0; n < 256; n++ {
+		neighborhood := Neighborhood(n)
+		count := neighborhood.Count()
+		letter := neighborhood.Letter()
+
+		switch {
+		case count == 0 || count == 8:
+			if letter != 0 {
+				t.Fatalf("Configuration of %d neighbors has letter %c\n", count, letter)
+			}
+			continue
+		case letter == 0 || strings.IndexByte(henselOrder[count], letter) < 0:
+			t.Fatalf("Configuration %08b of %d neighbors has invalid letter %q\n", n, count, letter)
+		}
+
+		// The letter does not depend on the orientation of the configuration
+		for _, image := range neighborhood.symmetries() {
+			if image.Letter() != letter {
+				t.Fatalf("Configuration %08b is %d%c but its image %08b is %d%c\n", n, count, letter, image, count, image.Letter())
+			}
+		}
+
+		if letters[count] == nil {
+			letters[count] = make(map[byte]bool)
+		}
+		letters[count][letter] = true
+	}
+
+	// Every letter names one of the distinct configurations
+	for count := 1; count < 8; count++ {
+		if len(letters[count]) != len(henselOrder[count]) {
+			t.Fatalf("Configurations of %d neighbors only have %d of the letters %s\n", count, len(letters[count]), henselOrder[count])
+		}
+	}
+}
+
+func TestNeighborhoodShapes(t *testing.T) {
+	shapes := []struct {
+		neighborhood Neighborhood
+		name         string
+	}{
+		{NeighborN | NeighborS, "2i"},
+		{NeighborNW | NeighborSE, "2n"},
+		{NeighborNW | NeighborN | NeighborNE, "3i"},
+		{NeighborNW | NeighborN | NeighborNE | NeighborS, "4t"},
+		{NeighborNW | NeighborW | NeighborS | NeighborSE, "4w"},
+		{NeighborNW | NeighborN | NeighborS | NeighborSE, "4z"},
+		{^(NeighborNW | NeighborN | NeighborNE), "5i"},
+		{^NeighborN, "7e"},
+	}
+
+	for _, shape := range shapes {
+		if name := fmt.Sprintf("%d%c", shape.neighborhood.Count(), shape.neighborhood.Letter()); name != shape.name {
+			t.Fatalf("Configuration %08b is %s instead of %s\n", shape.neighborhood, name, shape.name)
+		}
+	}
+}
+
+func TestNeighborsSelectorSelects(t *testing.T) {
+	for i := range neighborhoodOffsets {
+		direction := Neighborhood(1 << uint(i))
+		offset := neighborhoodOffsets[i]
+		orthogonal := offset.X == 0 || offset.Y == 0
+
+		if !NeighborsAll.selects(direction) {
+			t.Fatalf("All neighbors does not select %08b\n", direction)
+		}
+		if NeighborsOrthogonal.selects(direction) != orthogonal {
+			t.Fatalf("Orthogonal neighbors selecting %08b is %t\n", direction, !orthogonal)
+		}
+		if NeighborsOblique.selects(direction) == orthogonal {
+			t.Fatalf("Oblique neighbors selecting %08b is %t\n", direction, orthogonal)
+		}
+	}
+}
+
+// vim: set foldmethod=marker:
@@ -0,0 +1,236 @@
+package life
+
+import (
+	"errors"
+)
+
+// changeSet holds the organisms which were born and which died to produce a generation
+type changeSet struct {
+	born []Location
+	died []Location
+}
+
+func (t *changeSet) record(organism Location, alive bool) {
+	if alive {
+		t.born = append(t.born, organism)
+	} else {
+		t.died = append(t.died, organism)
+	}
+}
+
+// net returns the changes which remain after any organism which changed more than once is accounted for
+func (t *changeSet) net() *changeSet {
+	// The changes of an organism alternate between being born and dying
+	balance := make(map[Location]int)
+	for _, loc := range t.born {
+		balance[loc]++
+	}
+	for _, loc := range t.died {
+		balance[loc]--
+	}
+
+	net := new(changeSet)
+	for loc, count := range balance {
+		switch {
+		case count > 0:
+			net.born = append(net.born, loc)
+		case count < 0:
+			net.died = append(net.died, loc)
+		}
+	}
+	return net
+}
+
+// HistoryPolicy controls how much of its past a Life remembers
+type HistoryPolicy struct {
+	SnapshotInterval int // Number of generations between full snapshots of the living organisms
+	MaxGenerations   int // Once more generations than this are remembered, the oldest are forgotten. Zero remembers all of them
+}
+
+// DefaultHistoryPolicy is the history policy of a newly created Life
+var DefaultHistoryPolicy = HistoryPolicy{SnapshotInterval: 64, MaxGenerations: 4096}
+
+// history remembers the generations of a Life as snapshots taken at intervals
+// and the changes between each generation and the one before it
+type history struct {
+	policy    HistoryPolicy
+	oldest    int
+	newest    int
+	snapshots map[int][]Location
+	changes   map[int]*changeSet // The changes which produced each generation from the one before it
+}
+
+// contains tests if the given generation can be retrieved from the history
+func (t *history) contains(num int) bool {
+	return num >= t.oldest && num <= t.newest
+}
+
+// record adds a generation, which was produced by the given changes, to the history.
+// The living organisms are only retrieved when a snapshot is taken. Recording a generation
+// which is older than the newest one forgets the generations after it, which no longer follow from it.
+func (t *history) record(num int, living func() []Location, changes *changeSet) {
+	for future := num; future <= t.newest; future++ {
+		delete(t.snapshots, future)
+		delete(t.changes, future)
+	}
+
+	t.changes[num] = changes
+	if num%t.policy.SnapshotInterval == 0 {
+		t.snapshots[num] = living()
+	}
+	t.newest = num
+
+	t.evict()
+}
+
+// evict forgets the oldest generations which are past the maximum allowed by the policy
+func (t *history) evict() {
+	if t.policy.MaxGenerations <= 0 || t.newest-t.oldest <= t.policy.MaxGenerations {
+		return
+	}
+
+	// The remaining generations can still be reached by undoing the changes from a newer snapshot
+	oldest := t.newest - t.policy.MaxGenerations
+	for num := t.oldest; num < oldest; num++ {
+		delete(t.snapshots, num)
+		delete(t.changes, num+1)
+	}
+	t.oldest = oldest
+}
+
+// replay sets the states of the organisms which changed between the given generations, in either direction
+func (t *history) replay(from, to int, setAlive func(Location, bool)) {
+	for num := from + 1; num <= to; num++ {
+		for _, loc := range t.changes[num].died {
+			setAlive(loc, false)
+		}
+		for _, loc := range t.changes[num].born {
+			setAlive(loc, true)
+		}
+	}
+
+	for num := from; num > to; num-- {
+		for _, loc := range t.changes[num].born {
+			setAlive(loc, false)
+		}
+		for _, loc := range t.changes[num].died {
+			setAlive(loc, true)
+		}
+	}
+}
+
+// nearestSnapshot returns the snapshot which is closest to the given generation,
+// unless the given generation which is currently living is closer
+func (t *history) nearestSnapshot(num, currentNum int) (int, []Location, bool) {
+	distance := func(from int) int {
+		if from > num {
+			return from - num
+		}
+		return num - from
+	}
+
+	base, found := currentNum, false
+	var baseLiving []Location
+	before := num - (num % t.policy.SnapshotInterval)
+	for _, snapshot := range []int{before, before + t.policy.SnapshotInterval} {
+		if living, keyExists := t.snapshots[snapshot]; keyExists && distance(snapshot) < distance(base) {
+			base, baseLiving, found = snapshot, living, true
+		}
+	}
+	return base, baseLiving, found
+}
+
+// generation returns the living organisms of the given generation by replaying the fewest changes
+// from either the closest snapshot or the given generation which is currently living
+func (t *history) generation(num, currentNum int, current []Location) ([]Location, bool) {
+	if !t.contains(num) {
+		return nil, false
+	}
+
+	base, baseLiving, found := t.nearestSnapshot(num, currentNum)
+	if !found {
+		base, baseLiving = currentNum, current
+	}
+
+	living := make(map[Location]bool, len(baseLiving))
+	for _, loc := range baseLiving {
+		living[loc] = true
+	}
+	t.replay(base, num, func(loc Location, alive bool) {
+		if alive {
+			living[loc] = true
+		} else {
+			delete(living, loc)
+		}
+	})
+
+	all := make([]Location, 0, len(living))
+	for loc := range living {
+		all = append(all, loc)
+	}
+	return all, true
+}
+
+// dying returns the states of the organisms which are dying in the given generation, when organisms pass through
+// the given number of states. Organisms which died before the oldest generation in the history are not known.
+func (t *history) dying(num, states int) map[Location]int {
+	dying := make(map[Location]int)
+
+	// An organism which died in one of the last few generations has been dying since
+	for age := 0; age < states-StateAlive-1 && num-age > t.oldest; age++ {
+		for _, loc := range t.changes[num-age].died {
+			if _, keyExists := dying[loc]; !keyExists {
+				dying[loc] = StateAlive + 1 + age
+			}
+		}
+	}
+
+	return dying
+}
+
+// seek changes the organisms of the pond from the current generation to the given one
+func (t *history) seek(pond *pond, currentNum, num int) error {
+	if !t.contains(num) {
+		return errors.New("generation is not in the history")
+	}
+
+	// Starting from the closest snapshot keeps the changes to replay within one snapshot interval
+	base, snapshot, found := t.nearestSnapshot(num, currentNum)
+	if found {
+		living := make(map[Location]bool, len(snapshot))
+		for _, loc := range snapshot {
+			living[loc] = true
+		}
+		for _, loc := range pond.Living() {
+			if !living[loc] {
+				pond.SetAlive(loc, false)
+			}
+		}
+		pond.SetOrganisms(snapshot)
+	}
+
+	t.replay(base, num, pond.SetAlive)
+
+	return nil
+}
+
+func newHistory(policy HistoryPolicy, num int, living []Location) (*history, error) {
+	if policy.SnapshotInterval <= 0 {
+		return nil, errors.New("snapshot interval must be positive")
+	}
+	if policy.MaxGenerations < 0 {
+		return nil, errors.New("maximum number of generations cannot be negative")
+	}
+
+	t := new(history)
+
+	t.policy = policy
+	t.oldest = num
+	t.newest = num
+	t.snapshots = map[int][]Location{num: append([]Location(nil), living...)}
+	t.changes = make(map[int]*changeSet)
+
+	return t, nil
+}
+
+// vim: set foldmethod=marker:
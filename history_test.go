@@ -0,0 +1,142 @@
+package life
+
+import "testing"
+
+func TestHistoryPolicyInvalid(t *testing.T) {
+	if _, err := newHistory(HistoryPolicy{SnapshotInterval: 0}, 0, nil); err == nil {
+		t.Error("Did not reject a snapshot interval of zero")
+	}
+	if _, err := newHistory(HistoryPolicy{SnapshotInterval: 1, MaxGenerations: -1}, 0, nil); err == nil {
+		t.Error("Did not reject a negative maximum number of generations")
+	}
+}
+
+func TestHistoryGeneration(t *testing.T) {
+	// A single organism walks to the right, one cell per generation
+	walk := func(num int) []Location { return []Location{{X: num, Y: 0}} }
+
+	hist, err := newHistory(HistoryPolicy{SnapshotInterval: 4, MaxGenerations: 0}, 0, walk(0))
+	if err != nil {
+		t.Fatalf("Unable to create history: %s\n", err)
+	}
+	for num := 1; num <= 10; num++ {
+		hist.record(num, func() []Location { return walk(num) }, &changeSet{born: walk(num), died: walk(num - 1)})
+	}
+
+	for num := 0; num <= 10; num++ {
+		living, found := hist.generation(num, 10, walk(10))
+		if !found {
+			t.Fatalf("Generation %d was not found\n", num)
+		}
+		testLocationsMatch(t, walk(num), living)
+	}
+
+	if _, found := hist.generation(11, 10, walk(10)); found {
+		t.Error("Found a generation which was never recorded")
+	}
+}
+
+func TestHistoryEvict(t *testing.T) {
+	walk := func(num int) []Location { return []Location{{X: num, Y: 0}} }
+
+	hist, err := newHistory(HistoryPolicy{SnapshotInterval: 4, MaxGenerations: 5}, 0, walk(0))
+	if err != nil {
+		t.Fatalf("Unable to create history: %s\n", err)
+	}
+	for num := 1; num <= 10; num++ {
+		hist.record(num, func() []Location { return walk(num) }, &changeSet{born: walk(num), died: walk(num - 1)})
+	}
+
+	if hist.contains(4) {
+		t.Error("Generation 4 was not forgotten")
+	}
+	if len(hist.changes) != 5 {
+		t.Errorf("Remembered the changes of %d generations instead of 5\n", len(hist.changes))
+	}
+
+	// The oldest remaining generation comes before the oldest remaining snapshot
+	living, found := hist.generation(5, 10, walk(10))
+	if !found {
+		t.Fatal("Generation 5 was not found")
+	}
+	testLocationsMatch(t, walk(5), living)
+}
+
+func TestHistorySeekFromSnapshot(t *testing.T) {
+	walk := func(num int) []Location { return []Location{{X: num, Y: 0}} }
+
+	hist, err := newHistory(HistoryPolicy{SnapshotInterval: 4, MaxGenerations: 0}, 0, walk(0))
+	if err != nil {
+		t.Fatalf("Unable to create history: %s\n", err)
+	}
+	for num := 1; num <= 40; num++ {
+		hist.record(num, func() []Location { return walk(num) }, &changeSet{born: walk(num), died: walk(num - 1)})
+	}
+
+	// Only the changes after the closest snapshot are replayed, so these are never seen
+	for num := 7; num <= 40; num++ {
+		hist.changes[num] = &changeSet{born: []Location{{X: num, Y: 99}}}
+	}
+
+	p, err := newPond(Dimensions{}, newTracker(), NeighborsAll, TopologyUnbounded)
+	if err != nil {
+		t.Fatalf("Unable to create pond: %s\n", err)
+	}
+	p.SetOrganisms(walk(40))
+
+	if err := hist.seek(p, 40, 5); err != nil {
+		t.Fatalf("Unable to seek: %s\n", err)
+	}
+	testLocationsMatch(t, walk(5), p.Living())
+}
+
+func TestHistoryRecordAfterRewind(t *testing.T) {
+	walk := func(num int) []Location { return []Location{{X: num, Y: 0}} }
+	climb := func(num int) []Location { return []Location{{X: 6, Y: num - 6}} }
+
+	hist, err := newHistory(HistoryPolicy{SnapshotInterval: 4, MaxGenerations: 0}, 0, walk(0))
+	if err != nil {
+		t.Fatalf("Unable to create history: %s\n", err)
+	}
+	for num := 1; num <= 10; num++ {
+		hist.record(num, func() []Location { return walk(num) }, &changeSet{born: walk(num), died: walk(num - 1)})
+	}
+
+	// After going back to generation 6 the organism climbs instead
+	hist.record(7, func() []Location { return climb(7) }, &changeSet{born: climb(7), died: walk(6)})
+
+	if hist.contains(8) {
+		t.Error("Generation 8 is still remembered after the past was changed")
+	}
+	if _, keyExists := hist.snapshots[8]; keyExists {
+		t.Error("Snapshot of generation 8 is still remembered after the past was changed")
+	}
+
+	living, found := hist.generation(7, 7, climb(7))
+	if !found {
+		t.Fatal("Generation 7 was not found")
+	}
+	testLocationsMatch(t, climb(7), living)
+
+	living, found = hist.generation(5, 7, climb(7))
+	if !found {
+		t.Fatal("Generation 5 was not found")
+	}
+	testLocationsMatch(t, walk(5), living)
+}
+
+func TestChangeSetNet(t *testing.T) {
+	changes := new(changeSet)
+	changes.record(Location{X: 0, Y: 0}, true)
+	changes.record(Location{X: 1, Y: 0}, false)
+	changes.record(Location{X: 0, Y: 0}, false)
+	changes.record(Location{X: 2, Y: 0}, true)
+	changes.record(Location{X: 2, Y: 0}, false)
+	changes.record(Location{X: 2, Y: 0}, true)
+
+	net := changes.net()
+	testLocationsMatch(t, []Location{{X: 2, Y: 0}}, net.born)
+	testLocationsMatch(t, []Location{{X: 1, Y: 0}}, net.died)
+}
+
+// vim: set foldmethod=marker:
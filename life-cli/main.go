@@ -2,8 +2,10 @@ package main
 
 import (
 	"bufio"
+	"context"
 	"flag"
 	"fmt"
+	"math/rand"
 	"os"
 	"time"
 
@@ -25,51 +27,54 @@ func displaypond(strategy *life.Life, rate time.Duration, iterations int, static
 		reader.ReadString('\n')
 	}
 
-	updates := make(chan *life.Generation)
-	rateLimitedUpdates := make(chan *life.Generation)
-	stop := strategy.Start(updates)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
 
+	updates := make(chan *life.Generation)
+	finished := make(chan error, 1)
 	go func() {
-		countGenerations := 1
-		ticker := time.NewTicker(rate)
-
-		for {
-			select {
-			case <-ticker.C:
-				gen := <-rateLimitedUpdates
-				if static {
-					fmt.Print("\033[H")
-				}
-				fmt.Printf("Generation: %d\n", gen.Num)
-				fmt.Print(strategy)
-
-				if iterations >= 0 {
-					countGenerations++
-					if countGenerations >= iterations {
-						ticker.Stop()
-						stop()
-						break
-					}
-				}
-			}
-		}
+		finished <- strategy.Run(ctx, updates)
 	}()
 
-	for {
-		select {
-		case gen := <-updates:
-			rateLimitedUpdates <- gen
+	ticker := time.NewTicker(rate)
+	defer ticker.Stop()
+
+	countGenerations := 1
+	for gen := range updates {
+		<-ticker.C
+		if static {
+			fmt.Print("\033[H")
+		}
+		fmt.Printf("Generation: %d\n", gen.Num)
+		fmt.Print(strategy)
+
+		if iterations >= 0 {
+			countGenerations++
+			if countGenerations >= iterations {
+				cancel()
+			}
 		}
 	}
+
+	if err := <-finished; err != nil && err != context.Canceled {
+		fmt.Printf("Simulation stopped: %s\n", err)
+	}
 }
 
-func displayTestpond(width int, height int, rate time.Duration, initializer func(life.Dimensions, life.Location) []life.Location) {
+func displayTestpond(width int, height int, rate time.Duration, topology life.Topology, rules *life.Rules, processor life.Processor, initializer func(life.Dimensions, life.Location) []life.Location) {
 	strategy, err := life.New(
 		life.Dimensions{Height: height, Width: width},
 		life.NeighborsAll,
+		topology,
 		initializer,
-		life.ConwayTester(),
-		life.SimultaneousProcessor)
+		life.RulesTester(rules),
+		processor)
+	if err == nil && rules.States > 2 {
+		err = strategy.SetStates(rules.States)
+	}
+	if err == nil && !rules.Totalistic() {
+		strategy.SetNeighborhoodRules(life.NeighborhoodTester(rules))
+	}
 	if err == nil {
 		displaypond(strategy, rate, -1, true, true)
 	} else {
@@ -79,62 +84,90 @@ func displayTestpond(width int, height int, rate time.Duration, initializer func
 
 func main() {
 
-	patternPtr := flag.String("pattern", "random", "Specify the pattern to run")
+	patterns := "random"
+	for _, pattern := range life.Catalog() {
+		patterns += ", " + pattern.Name
+	}
+	patternPtr := flag.String("pattern", "random", "Pattern to run ("+patterns+")")
 	widthPtr := flag.Int("width", 1, "Width of the Life board")
 	heightPtr := flag.Int("height", 1, "Height of the Life board")
 	ratePtr := flag.Duration("rate", 1, "Rate at which the board should be updated")
 	extraPtr := flag.Int("extra", -1, "Extra values for pattners (such as random)")
+	rulesPtr := flag.String("rules", "B3/S23", "Rulestring of the rules to run the simulation with, such as B3/S23, the Generations rules 345/2/4 or the isotropic non-totalistic rules B2-a/S12")
+	topologyPtr := flag.String("topology", "Plane", "Shape of the board (Plane, Torus, Unbounded, KleinBottle, CrossSurface, Cylinder)")
+	processorPtr := flag.String("processor", "simultaneous", "Processor to run the simulation with (simultaneous, parallel, bitboard, hashlife)")
+	filePtr := flag.String("file", "", "Pattern file to run (RLE, plaintext, Life 1.05, Life 1.06 or macrocell)")
+	seedPtr := flag.Int64("seed", 0, "Seed of the random pattern, which is picked at random when zero")
+	symmetryPtr := flag.String("symmetry", "C1", "Symmetry of the random pattern (C1, C2, C4, D2, D4, D8)")
 
 	flag.Parse()
 
-	switch *patternPtr {
-	case "blinkers":
-		width := 9
-		if *widthPtr > width {
-			width = *widthPtr
-		}
-		height := 9
-		if *heightPtr > height {
-			height = *heightPtr
-		}
+	rules, err := life.ParseRules(*rulesPtr)
+	if err != nil {
+		fmt.Printf("Could not parse rules: %s\n", err)
+		os.Exit(1)
+	}
 
-		displayTestpond(width, height, *ratePtr, life.Blinkers)
-	case "toads":
-		width := 10
-		if *widthPtr > width {
-			width = *widthPtr
+	topology, err := life.ParseTopology(*topologyPtr)
+	if err != nil {
+		fmt.Printf("Could not parse topology: %s\n", err)
+		os.Exit(1)
+	}
+
+	var processor life.Processor
+	switch *processorPtr {
+	case "simultaneous":
+		processor = life.SimultaneousProcessor
+	case "parallel":
+		processor = life.ParallelProcessor
+	case "bitboard":
+		processor = life.BitboardProcessor
+	case "hashlife":
+		processor = life.NewHashLifeProcessor()
+	default:
+		fmt.Println("Did not recognize processor")
+		os.Exit(1)
+	}
+
+	if len(*filePtr) > 0 {
+		*patternPtr = "file"
+	}
+
+	switch *patternPtr {
+	case "file":
+		file, err := os.Open(*filePtr)
+		if err != nil {
+			fmt.Printf("Could not open pattern file: %s\n", err)
+			os.Exit(1)
 		}
-		height := 10
-		if *heightPtr > height {
-			height = *heightPtr
+		pattern, err := life.ReadPattern(file)
+		file.Close()
+		if err != nil {
+			fmt.Printf("Could not read pattern file: %s\n", err)
+			os.Exit(1)
 		}
 
-		displayTestpond(width, height, *ratePtr, life.Toads)
-	case "glider":
-		width := 30
-		if *widthPtr > width {
-			width = *widthPtr
-		}
-		height := 30
-		if *heightPtr > height {
-			height = *heightPtr
+		// Use the rules of the pattern unless told otherwise
+		rulesGiven := false
+		flag.Visit(func(f *flag.Flag) {
+			if f.Name == "rules" {
+				rulesGiven = true
+			}
+		})
+		if pattern.Rules != nil && !rulesGiven {
+			rules = pattern.Rules
 		}
 
-		displayTestpond(width, height, *ratePtr,
-			func(dimensions life.Dimensions, offset life.Location) []life.Location {
-				return life.Gliders(life.Dimensions{Height: 4, Width: 4}, offset)
-			})
-	case "pulsar":
-		width := 15
+		width := pattern.Dims.Width
 		if *widthPtr > width {
 			width = *widthPtr
 		}
-		height := 15
+		height := pattern.Dims.Height
 		if *heightPtr > height {
 			height = *heightPtr
 		}
 
-		displayTestpond(width, height, *ratePtr, life.Pulsar)
+		displayTestpond(width, height, *ratePtr, topology, rules, processor, pattern.Initializer())
 	case "random":
 		width := 120
 		if *widthPtr > width {
@@ -150,12 +183,57 @@ func main() {
 			percentCoverage = *extraPtr
 		}
 
-		displayTestpond(width, height, *ratePtr,
+		symmetry, err := life.ParseSoupSymmetry(*symmetryPtr)
+		if err != nil {
+			fmt.Printf("Could not parse symmetry: %s\n", err)
+			os.Exit(1)
+		}
+
+		seed := *seedPtr
+		if seed == 0 {
+			seed = time.Now().UnixNano()
+		}
+		fmt.Fprintf(os.Stderr, "Random seed: %d\n", seed)
+
+		displayTestpond(width, height, *ratePtr, topology, rules, processor,
 			func(dimensions life.Dimensions, offset life.Location) []life.Location {
-				return life.Random(dimensions, offset, percentCoverage)
+				return life.Soup(dimensions, offset, percentCoverage, symmetry, rand.New(rand.NewSource(seed)))
 			})
 	default:
-		fmt.Println("Did not recognize pattern")
+		pattern, found := life.LookupPattern(*patternPtr)
+		if !found {
+			fmt.Println("Did not recognize pattern")
+			os.Exit(1)
+		}
+
+		size := pattern.Dims.Width
+		if pattern.Dims.Height > size {
+			size = pattern.Dims.Height
+		}
+
+		// Stationary patterns fill the board while the others get room to move
+		initializer := pattern.Fill
+		switch pattern.Category {
+		case life.CategoryStillLife, life.CategoryOscillator:
+			size += 6
+		default:
+			size *= 3
+			if size < 30 {
+				size = 30
+			}
+			initializer = pattern.Initializer()
+		}
+
+		width := size
+		if *widthPtr > width {
+			width = *widthPtr
+		}
+		height := size
+		if *heightPtr > height {
+			height = *heightPtr
+		}
+
+		displayTestpond(width, height, *ratePtr, topology, rules, processor, initializer)
 	}
 }
 
@@ -2,57 +2,252 @@ package life
 
 import (
 	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"sync"
 )
 
 // Generation encapsulates a snapshot of each generation
 type Generation struct {
 	Num    int
 	Living []Location
+	Born   []Location       // The organisms which came alive since the previous generation
+	Died   []Location       // The organisms which died since the previous generation
+	Dying  map[Location]int // The states of the organisms which are dying, when organisms have more than two states
+}
+
+type generationContents int
+
+// Enumeration of what the generations produced by a running simulation contain
+const (
+	GenerationLiving generationContents = iota
+	GenerationDeltas
+	GenerationLivingAndDeltas
+)
+
+func (t generationContents) String() string {
+	switch t {
+	case GenerationLiving:
+		return "Living"
+	case GenerationDeltas:
+		return "Deltas"
+	case GenerationLivingAndDeltas:
+		return "LivingAndDeltas"
+	}
+	return "Unknown"
 }
 
 // Life structure is the primary structure for the simulation
 type Life struct {
-	pond        *pond
-	processor   func(pond *pond, rules func(int, bool) bool)
-	ruleset     func(int, bool) bool
-	Seed        []Location
-	Generations int
+	mutex         sync.Mutex // Guards the pond and the controls
+	paused        bool
+	resumed       chan struct{} // Closed when a paused simulation is resumed
+	pond          *pond
+	history       *history
+	contents      generationContents
+	stability     *stabilityDetector // Only set when stability is being detected
+	haltStable    bool               // Stop running once the simulation is stable
+	processor     Processor
+	ruleset       func(int, bool) bool
+	neighborhoods func(Neighborhood, bool) bool // Replaces the ruleset when it is set
+	Seed          []Location
+	Generations   int
 }
 
-func (t *Life) process() *Generation {
-	// Process any organisms that need to be
-	t.processor(t.pond, t.ruleset)
+func (t *Life) process() (*Generation, error) {
+	// Process any organisms that need to be, keeping track of what changed
+	changes := new(changeSet)
+	t.pond.changes = changes
+	err := t.processPond(t.pond)
+	t.pond.changes = nil
+	if err != nil {
+		return nil, err
+	}
 
 	// Update the pond's statistics
 	t.Generations++
 
-	return &Generation{Num: t.Generations, Living: t.pond.living.GetAll()}
+	t.history.record(t.Generations, t.pond.living.GetAll, changes)
+	if t.stability != nil {
+		t.stability.observe(t.Generations, t.pond.living.GetAll())
+	}
+
+	return t.generation(changes), nil
 }
 
-// Start enables the seeded simulation with each tick providing a Generation object
-func (t *Life) Start(listener chan *Generation) func() {
-	stop := false
+// processPond advances the given pond by a single generation with the processor and the rules of the simulation.
+// Rules which are given the configuration of the neighbors are handed to the SimultaneousProcessor
+// when the processor cannot apply them.
+func (t *Life) processPond(p *pond) error {
+	if t.neighborhoods == nil {
+		return t.processor.Process(p, t.ruleset)
+	}
 
-	go func() {
-		for {
-			if stop {
-				break
-			} else {
-				if listener != nil {
-					listener <- t.process()
-				}
+	if processor, ok := t.processor.(NeighborhoodProcessor); ok {
+		return processor.ProcessNeighborhoods(p, t.neighborhoods)
+	}
+	return SimultaneousProcessor.ProcessNeighborhoods(p, t.neighborhoods)
+}
+
+// generation creates the snapshot of the current generation, which was produced by the given changes
+func (t *Life) generation(changes *changeSet) *Generation {
+	gen := &Generation{Num: t.Generations}
+	if t.contents != GenerationDeltas {
+		gen.Living = t.pond.living.GetAll()
+		if t.pond.states > 2 {
+			gen.Dying = make(map[Location]int, len(t.pond.dying))
+			for loc, state := range t.pond.dying {
+				gen.Dying[loc] = state
+			}
+		}
+	}
+	if t.contents != GenerationLiving {
+		gen.Born = append([]Location{}, changes.born...)
+		gen.Died = append([]Location{}, changes.died...)
+	}
+	return gen
+}
+
+// SetGenerationContents selects what the generations which are produced by Run, Step, Seek and Rewind contain.
+// By default they only contain the living organisms. Generations which only contain the organisms which were
+// born and which died are much smaller than the full board.
+func (t *Life) SetGenerationContents(contents generationContents) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	t.contents = contents
+}
+
+// Run processes one generation after another, sending each to the listener, until the given context is done.
+// The listener is closed when Run returns, which is either when the context is done, in which case the
+// context's error is returned, when a generation could not be processed, or when the simulation has
+// become stable if DetectStability was asked to halt it, in which case nil is returned.
+func (t *Life) Run(ctx context.Context, listener chan *Generation) error {
+	if listener != nil {
+		defer close(listener)
+	}
+	return t.run(ctx, listener)
+}
+
+func (t *Life) run(ctx context.Context, listener chan *Generation) error {
+	for {
+		// Wait for the simulation to be resumed
+		t.mutex.Lock()
+		if t.paused {
+			resumed := t.resumed
+			t.mutex.Unlock()
+
+			select {
+			case <-resumed:
+				continue
+			case <-ctx.Done():
+				return ctx.Err()
 			}
 		}
-	}()
 
-	return func() {
-		stop = true
+		select {
+		case <-ctx.Done():
+			t.mutex.Unlock()
+			return ctx.Err()
+		default:
+		}
+
+		gen, err := t.process()
+		halt := t.haltStable && t.stability.stability.State != StabilityEvolving
+		t.mutex.Unlock()
+		if err != nil {
+			return err
+		}
+
+		if listener != nil {
+			select {
+			case listener <- gen:
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+
+		if halt {
+			return nil
+		}
+	}
+}
+
+// Pause stops a running simulation from processing any more generations until it is resumed
+func (t *Life) Pause() {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	if !t.paused {
+		t.paused = true
+		t.resumed = make(chan struct{})
 	}
 }
 
+// Resume lets a paused simulation continue
+func (t *Life) Resume() {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	if t.paused {
+		t.paused = false
+		close(t.resumed)
+	}
+}
+
+// Paused tests if the simulation is paused
+func (t *Life) Paused() bool {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	return t.paused
+}
+
+// Step processes the given number of generations, even while paused, and returns the last of them.
+// The generations are not sent to the listener of a running simulation.
+func (t *Life) Step(num int) (*Generation, error) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	gen := t.generation(new(changeSet))
+	for i := 0; i < num; i++ {
+		var err error
+		if gen, err = t.process(); err != nil {
+			return nil, err
+		}
+	}
+
+	return gen, nil
+}
+
+// Start enables the seeded simulation with each tick providing a Generation object
+//
+// Deprecated: Start cannot report errors and never closes the listener. Use Run instead.
+func (t *Life) Start(listener chan *Generation) func() {
+	ctx, cancel := context.WithCancel(context.Background())
+	go t.run(ctx, listener)
+	return cancel
+}
+
 // Generation provides the snapshot of the given generation
-// It will actually simulate the full progression from seed to the given generation
+// Generations which are in the history are rebuilt from it. Otherwise it will actually simulate the progression
+// from the current generation, or from the seed for earlier generations, unless the processor is able to jump
+// directly to the given generation
 func (t *Life) Generation(num int) *Generation {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	if num != t.Generations {
+		if living, found := t.history.generation(num, t.Generations, t.pond.living.GetAll()); found {
+			gen := &Generation{Num: num, Living: living}
+			if t.pond.states > 2 {
+				gen.Dying = t.history.dying(num, t.pond.states)
+			}
+			return gen
+		}
+	}
+
 	var p *pond
 	if num == t.Generations {
 		p = t.pond
@@ -62,23 +257,172 @@ func (t *Life) Generation(num int) *Generation {
 			// logf("Unable to clone pond: %s\n", err)
 			return nil // FIXME
 		}
-		cloned.SetOrganisms(t.Seed)
-		for i := 0; i < num; i++ {
-			t.processor(cloned, t.ruleset)
+
+		start := t.Generations
+		if num < t.Generations {
+			for _, loc := range cloned.Living() {
+				cloned.SetAlive(loc, false)
+			}
+			cloned.dying = make(map[Location]int)
+			cloned.SetOrganisms(t.Seed)
+			start = 0
+		}
+
+		jumped := false
+		if jumper, ok := t.processor.(generationJumper); ok {
+			if t.neighborhoods != nil {
+				jumped, err = jumper.jumpNeighborhoods(cloned, t.neighborhoods, num-start)
+			} else {
+				jumped, err = jumper.jump(cloned, t.ruleset, num-start)
+			}
+			if err != nil {
+				return nil // FIXME
+			}
+		}
+		for i := start; i < num && !jumped; i++ {
+			if err := t.processPond(cloned); err != nil {
+				return nil // FIXME
+			}
 		}
 
 		p = cloned
 	}
 
-	return &Generation{Num: num, Living: p.living.GetAll()}
+	gen := &Generation{Num: num, Living: p.living.GetAll()}
+	if p.states > 2 {
+		gen.Dying = make(map[Location]int, len(p.dying))
+		for loc, state := range p.dying {
+			gen.Dying[loc] = state
+		}
+	}
+	return gen
 }
 
-// Dimensions returns the dimensions of the Life board
+// Seek sets the simulation back, or forward, to the given generation, which must be in the history
+func (t *Life) Seek(num int) (*Generation, error) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	return t.seek(num)
+}
+
+// Rewind sets the simulation back by the given number of generations
+func (t *Life) Rewind(num int) (*Generation, error) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	return t.seek(t.Generations - num)
+}
+
+// seek changes the pond to the given generation. The deltas of the returned generation
+// are the changes from the generation which was current before seeking.
+func (t *Life) seek(num int) (*Generation, error) {
+	changes := new(changeSet)
+	t.pond.changes = changes
+	err := t.history.seek(t.pond, t.Generations, num)
+	t.pond.changes = nil
+	if err != nil {
+		return nil, fmt.Errorf("unable to seek to generation %d: %s", num, err)
+	}
+	t.Generations = num
+
+	if t.pond.states > 2 {
+		t.pond.dying = t.history.dying(num, t.pond.states)
+	}
+
+	// Whatever was detected no longer applies
+	if t.stability != nil {
+		t.stability = newStabilityDetector()
+		t.stability.observe(t.Generations, t.pond.living.GetAll())
+	}
+
+	return t.generation(changes.net()), nil
+}
+
+// SetHistoryPolicy changes how much of its past the simulation remembers.
+// Everything which was remembered before the current generation is forgotten.
+func (t *Life) SetHistoryPolicy(policy HistoryPolicy) error {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	history, err := newHistory(policy, t.Generations, t.pond.living.GetAll())
+	if err != nil {
+		return err
+	}
+	t.history = history
+
+	return nil
+}
+
+// SetStates sets the number of states the organisms can be in, including alive and dead, which is two by default.
+// With more states the simulation follows the rules of the Generations family, where an organism which does not
+// survive passes through the extra states, one generation at a time, before it is dead and can be born again.
+// Any organisms which are dying are killed.
+func (t *Life) SetStates(states int) error {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	if states < 2 {
+		return errors.New("organisms must have at least two states")
+	}
+
+	t.pond.states = states
+	t.pond.dying = make(map[Location]int)
+
+	return nil
+}
+
+// SetNeighborhoodRules replaces the rules with ones which are given the configuration of the living neighbors of
+// each organism rather than how many there are, such as the isotropic non-totalistic rules of NeighborhoodTester.
+// Processors which are not a NeighborhoodProcessor hand the pond to the SimultaneousProcessor to apply them.
+// Setting them to nil restores the rules the simulation was created with.
+func (t *Life) SetNeighborhoodRules(rules func(Neighborhood, bool) bool) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	t.neighborhoods = rules
+}
+
+// DetectStability starts watching for the simulation to become a still life, enter a cycle or die out,
+// which Stability then reports. If halt is set, Run and Start stop once any of those happen.
+func (t *Life) DetectStability(halt bool) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	if t.stability == nil {
+		t.stability = newStabilityDetector()
+		t.stability.observe(t.Generations, t.pond.living.GetAll())
+	}
+	t.haltStable = halt
+}
+
+// Stability returns what the simulation has settled into, which is always StabilityEvolving
+// unless DetectStability was called
+func (t *Life) Stability() Stability {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	if t.stability == nil {
+		return Stability{State: StabilityEvolving}
+	}
+	return t.stability.stability
+}
+
+// Dimensions returns the dimensions of the Life board.
+// With an unbounded topology this is the size of the
+// bounding box of the currently living organisms.
 func (t *Life) Dimensions() Dimensions {
-	return t.pond.Dims
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	_, dims := t.pond.Bounds()
+	return dims
 }
 
 func (t *Life) String() string {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
 	var buf bytes.Buffer
 
 	buf.WriteString("\n")
@@ -90,14 +434,20 @@ func (t *Life) String() string {
 // New creates a new Life structure
 func New(dims Dimensions,
 	neighbors neighborsSelector,
+	topology Topology,
 	initializer func(Dimensions, Location) []Location,
 	rules func(int, bool) bool,
-	processor func(pond *pond, rules func(int, bool) bool)) (*Life, error) {
+	processor Processor) (*Life, error) {
 	s := new(Life)
 
+	// Let the processor decide how the living organisms are stored
+	var living cellStore = newTracker()
+	if provider, ok := processor.(storeProvider); ok {
+		living = provider.newStore(dims)
+	}
+
 	var err error
-	livingTracker := newTracker()
-	s.pond, err = newPond(dims, livingTracker, neighbors)
+	s.pond, err = newPond(dims, living, neighbors, topology)
 	if err != nil {
 		return nil, err
 	}
@@ -110,6 +460,11 @@ func New(dims Dimensions,
 	s.Seed = initializer(s.pond.Dims, Location{})
 	s.pond.SetOrganisms(s.Seed)
 
+	s.history, err = newHistory(DefaultHistoryPolicy, 0, s.pond.living.GetAll())
+	if err != nil {
+		return nil, err
+	}
+
 	return s, nil
 }
 
@@ -0,0 +1,129 @@
+package life
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+const life105Header = "#Life 1.05"
+
+// ReadLife105 decodes a pattern in the Life 1.05 format, which is made up of positioned blocks of cells
+func ReadLife105(r io.Reader) (*PatternFile, error) {
+	pattern := &PatternFile{Living: make([]Location, 0)}
+
+	var block Location
+	y := 0
+
+	scanner := bufio.NewScanner(r)
+	for lineNum := 1; scanner.Scan(); lineNum++ {
+		line := strings.TrimSpace(scanner.Text())
+
+		switch {
+		case len(line) == 0:
+			continue
+		case strings.HasPrefix(line, "#"):
+			if len(line) < 2 {
+				continue
+			}
+			val := strings.TrimSpace(line[2:])
+			switch line[1] {
+			case 'D', 'C':
+				pattern.Comments = append(pattern.Comments, val)
+			case 'N':
+				pattern.Rules = GetConwayRules()
+			case 'R':
+				rules, err := ParseRules(val)
+				if err != nil {
+					return nil, fmt.Errorf("line %d: %s", lineNum, err)
+				}
+				pattern.Rules = rules
+			case 'P':
+				if _, err := fmt.Sscanf(val, "%d %d", &block.X, &block.Y); err != nil {
+					return nil, fmt.Errorf("line %d: invalid block position %q", lineNum, val)
+				}
+				y = 0
+			}
+			continue
+		}
+
+		for x, c := range line {
+			switch c {
+			case '.':
+			case '*', 'O', 'o':
+				pattern.Living = append(pattern.Living, Location{X: block.X + x, Y: block.Y + y})
+			default:
+				return nil, fmt.Errorf("line %d: unexpected character '%c' in pattern", lineNum, c)
+			}
+		}
+		y++
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	_, pattern.Dims = BoundingBox(pattern.Living)
+
+	return pattern, nil
+}
+
+// WriteLife105 encodes the given pattern in the Life 1.05 format.
+// The name and author are written as the first description lines.
+func WriteLife105(w io.Writer, pattern *PatternFile) error {
+	var buf bytes.Buffer
+
+	buf.WriteString(life105Header + "\n")
+
+	if len(pattern.Name) > 0 {
+		buf.WriteString("#D " + pattern.Name + "\n")
+	}
+	if len(pattern.Author) > 0 {
+		buf.WriteString("#D " + pattern.Author + "\n")
+	}
+	for _, comment := range pattern.Comments {
+		buf.WriteString("#D " + comment + "\n")
+	}
+
+	if pattern.Rules == nil || pattern.Rules.Rulestring() == GetConwayRules().Rulestring() {
+		buf.WriteString("#N\n")
+	} else {
+		buf.WriteString("#R " + pattern.Rules.String() + "\n")
+	}
+
+	origin, dims := BoundingBox(pattern.Living)
+	buf.WriteString(fmt.Sprintf("#P %d %d\n", origin.X, origin.Y))
+
+	// Organize the living cells by row
+	rows := make([][]int, dims.Height)
+	for _, loc := range pattern.Living {
+		rows[loc.Y-origin.Y] = append(rows[loc.Y-origin.Y], loc.X-origin.X)
+	}
+
+	for _, row := range rows {
+		sort.Ints(row)
+
+		// Trailing dead cells are not written
+		x := 0
+		for _, col := range row {
+			if col < x {
+				continue
+			}
+			buf.WriteString(strings.Repeat(".", col-x))
+			buf.WriteString("*")
+			x = col + 1
+		}
+		if len(row) == 0 {
+			buf.WriteString(".")
+		}
+		buf.WriteString("\n")
+	}
+
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+// vim: set foldmethod=marker:
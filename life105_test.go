@@ -0,0 +1,105 @@
+package life
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestReadLife105(t *testing.T) {
+	life105 := `#Life 1.05
+#D Two toads
+#R 23/36
+#P 0 0
+.***
+***.
+#P 10 10
+.***
+***.
+`
+
+	pattern, err := ReadLife105(strings.NewReader(life105))
+	if err != nil {
+		t.Fatalf("Unable to read Life 1.05: %s\n", err)
+	}
+
+	if pattern.Rules == nil || pattern.Rules.Rulestring() != "B36/S23" {
+		t.Error("Did not read the expected rules")
+	}
+	if len(pattern.Comments) != 1 {
+		t.Errorf("Read %d comments instead of 1\n", len(pattern.Comments))
+	}
+
+	toad := func(offset Location) []Location {
+		return Toads(Dimensions{Width: 4, Height: 4}, Location{X: offset.X, Y: offset.Y - 1})
+	}
+	testLocationsMatch(t, append(toad(Location{}), toad(Location{X: 10, Y: 10})...), pattern.Living)
+}
+
+func TestReadLife105Centered(t *testing.T) {
+	life105 := `#Life 1.05
+#D Glider
+#N
+#P -1 -1
+.*.
+..*
+***
+`
+
+	pattern, err := ReadLife105(strings.NewReader(life105))
+	if err != nil {
+		t.Fatalf("Unable to read Life 1.05: %s\n", err)
+	}
+	testLocationsMatch(t, Gliders(Dimensions{Width: 3, Height: 3}, Location{X: -1, Y: -1}), pattern.Living)
+
+	// The pattern is seeded from the top-left corner of the board, which keeps it on a bounded pond
+	offset := Location{X: 2, Y: 1}
+	testLocationsMatch(t, Gliders(Dimensions{Width: 3, Height: 3}, offset), pattern.Initializer()(Dimensions{Width: 8, Height: 8}, offset))
+
+	life, err := New(Dimensions{Width: 8, Height: 8}, NeighborsAll, TopologyTorus, pattern.Initializer(), ConwayTester(), SimultaneousProcessor)
+	if err != nil {
+		t.Fatalf("Unable to create strategy: %s\n", err)
+	}
+	if _, err := life.Step(1); err != nil {
+		t.Fatalf("Unable to step: %s\n", err)
+	}
+}
+
+func TestReadLife105Error(t *testing.T) {
+	bogus := []string{
+		"#Life 1.05\n#R 9/3\n#P 0 0\n.*.\n",
+		"#Life 1.05\n#P zero 0\n.*.\n",
+		"#Life 1.05\n#P 0 0\n.x.\n",
+	}
+
+	for _, life105 := range bogus {
+		if _, err := ReadLife105(strings.NewReader(life105)); err == nil {
+			t.Errorf("Unexpectedly read bogus Life 1.05 %q\n", life105)
+		}
+	}
+}
+
+func TestLife105RoundTrip(t *testing.T) {
+	expected := &PatternFile{
+		Rules:  &Rules{Survive: []int{2, 3}, Born: []int{3, 6}},
+		Living: Random(Dimensions{Width: 16, Height: 16}, Location{X: -3, Y: 7}, 50),
+	}
+
+	var buf bytes.Buffer
+	if err := WriteLife105(&buf, expected); err != nil {
+		t.Fatalf("Unable to write Life 1.05: %s\n", err)
+	}
+
+	actual, err := ReadLife105(&buf)
+	if err != nil {
+		t.Fatalf("Unable to read Life 1.05: %s\n", err)
+	}
+
+	if actual.Rules == nil || actual.Rules.Rulestring() != expected.Rules.Rulestring() {
+		t.Error("Rules did not survive the round trip")
+	}
+
+	testLocationsMatch(t, expected.Living, actual.Living)
+}
+
+// vim: set foldmethod=marker:
@@ -0,0 +1,80 @@
+package life
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+const life106Header = "#Life 1.06"
+
+// ReadLife106 decodes a pattern in the Life 1.06 format, which is a list of living coordinates
+func ReadLife106(r io.Reader) (*PatternFile, error) {
+	pattern := &PatternFile{Living: make([]Location, 0)}
+
+	scanner := bufio.NewScanner(r)
+	for lineNum := 1; scanner.Scan(); lineNum++ {
+		line := strings.TrimSpace(scanner.Text())
+
+		switch {
+		case len(line) == 0:
+			continue
+		case strings.HasPrefix(line, "#"):
+			// Not part of the format, but commonly found
+			if len(line) > 1 {
+				val := strings.TrimSpace(line[2:])
+				switch line[1] {
+				case 'N':
+					pattern.Name = val
+				case 'O':
+					pattern.Author = val
+				case 'C', 'D':
+					pattern.Comments = append(pattern.Comments, val)
+				}
+			}
+			continue
+		}
+
+		var loc Location
+		if _, err := fmt.Sscanf(line, "%d %d", &loc.X, &loc.Y); err != nil {
+			return nil, fmt.Errorf("line %d: invalid coordinate %q", lineNum, line)
+		}
+		pattern.Living = append(pattern.Living, loc)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	_, pattern.Dims = BoundingBox(pattern.Living)
+
+	return pattern, nil
+}
+
+// WriteLife106 encodes the given pattern in the Life 1.06 format
+func WriteLife106(w io.Writer, pattern *PatternFile) error {
+	var buf bytes.Buffer
+
+	buf.WriteString(life106Header + "\n")
+
+	living := make([]Location, len(pattern.Living))
+	copy(living, pattern.Living)
+	sort.Slice(living, func(i, j int) bool {
+		if living[i].Y != living[j].Y {
+			return living[i].Y < living[j].Y
+		}
+		return living[i].X < living[j].X
+	})
+
+	for _, loc := range living {
+		buf.WriteString(fmt.Sprintf("%d %d\n", loc.X, loc.Y))
+	}
+
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+// vim: set foldmethod=marker:
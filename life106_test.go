@@ -0,0 +1,48 @@
+package life
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestReadLife106(t *testing.T) {
+	life106 := `#Life 1.06
+1 0
+2 1
+0 2
+1 2
+2 2
+`
+
+	pattern, err := ReadLife106(strings.NewReader(life106))
+	if err != nil {
+		t.Fatalf("Unable to read Life 1.06: %s\n", err)
+	}
+
+	testLocationsMatch(t, Gliders(Dimensions{Width: 3, Height: 3}, Location{}), pattern.Living)
+}
+
+func TestReadLife106Error(t *testing.T) {
+	if _, err := ReadLife106(strings.NewReader("#Life 1.06\n1 a\n")); err == nil {
+		t.Error("Unexpectedly read bogus Life 1.06")
+	}
+}
+
+func TestLife106RoundTrip(t *testing.T) {
+	expected := Random(Dimensions{Width: 16, Height: 16}, Location{X: -8, Y: -8}, 50)
+
+	var buf bytes.Buffer
+	if err := WriteLife106(&buf, &PatternFile{Living: expected}); err != nil {
+		t.Fatalf("Unable to write Life 1.06: %s\n", err)
+	}
+
+	actual, err := ReadLife106(&buf)
+	if err != nil {
+		t.Fatalf("Unable to read Life 1.06: %s\n", err)
+	}
+
+	testLocationsMatch(t, expected, actual.Living)
+}
+
+// vim: set foldmethod=marker:
@@ -1,6 +1,8 @@
 package life
 
 import (
+	"context"
+	"errors"
 	"testing"
 	"time"
 )
@@ -10,6 +12,7 @@ func TestLifeCreation(t *testing.T) {
 	strategy, err := New(
 		dims,
 		NeighborsAll,
+		TopologyPlane,
 		Blinkers,
 		ConwayTester(),
 		SimultaneousProcessor)
@@ -17,7 +20,7 @@ func TestLifeCreation(t *testing.T) {
 		t.Fatalf("Unable to create strategy: %s\n", err)
 	}
 
-	expected, _ := newPond(Dimensions{Height: 3, Width: 3}, newTracker(), NeighborsAll)
+	expected, _ := newPond(Dimensions{Height: 3, Width: 3}, newTracker(), NeighborsAll, TopologyPlane)
 	expected.SetOrganisms([]Location{Location{X: 0, Y: 1}, Location{X: 1, Y: 1}, Location{X: 2, Y: 1}})
 
 	if !strategy.pond.Equals(expected) {
@@ -30,6 +33,7 @@ func TestLifeProcess(t *testing.T) {
 	strategy, err := New(
 		dims,
 		NeighborsAll,
+		TopologyPlane,
 		Blinkers,
 		ConwayTester(),
 		SimultaneousProcessor)
@@ -60,6 +64,7 @@ func TestLifeStart(t *testing.T) {
 	strategy, err := New(
 		dims,
 		NeighborsAll,
+		TopologyPlane,
 		func(dimensions Dimensions, offset Location) []Location {
 			return Random(dimensions, offset, 85)
 		},
@@ -94,11 +99,117 @@ func TestLifeStart(t *testing.T) {
 	}
 }
 
+func newTestBlinkers(t *testing.T, processor Processor) *Life {
+	life, err := New(
+		Dimensions{Height: 3, Width: 3},
+		NeighborsAll,
+		TopologyPlane,
+		Blinkers,
+		ConwayTester(),
+		processor)
+	if err != nil {
+		t.Fatalf("Unable to create strategy: %s\n", err)
+	}
+	return life
+}
+
+func TestLifeRun(t *testing.T) {
+	life := newTestBlinkers(t, SimultaneousProcessor)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	listener := make(chan *Generation)
+	finished := make(chan error, 1)
+	go func() {
+		finished <- life.Run(ctx, listener)
+	}()
+
+	for expected := 1; expected <= 3; expected++ {
+		if gen := <-listener; gen.Num != expected {
+			t.Fatalf("Received generation %d instead of %d\n", gen.Num, expected)
+		}
+	}
+
+	// Nobody is reading from the listener, but Run still returns
+	cancel()
+	if err := <-finished; err != context.Canceled {
+		t.Fatalf("Run returned %v instead of the context's error\n", err)
+	}
+
+	if _, more := <-listener; more {
+		t.Fatal("Listener was not closed")
+	}
+}
+
+func TestLifeRunError(t *testing.T) {
+	expected := errors.New("processing failed")
+	life := newTestBlinkers(t, ProcessorFunc(func(grid Grid, rules func(int, bool) bool) error {
+		return expected
+	}))
+
+	listener := make(chan *Generation)
+	if err := life.Run(context.Background(), listener); err != expected {
+		t.Fatalf("Run returned %v instead of %v\n", err, expected)
+	}
+
+	if _, more := <-listener; more {
+		t.Fatal("Listener was not closed")
+	}
+}
+
+func TestLifePauseResumeStep(t *testing.T) {
+	life := newTestBlinkers(t, SimultaneousProcessor)
+	life.Pause()
+	if !life.Paused() {
+		t.Fatal("Simulation was not paused")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	listener := make(chan *Generation)
+	go life.Run(ctx, listener)
+
+	// Nothing is processed while paused, other than by stepping
+	gen, err := life.Step(3)
+	if err != nil {
+		t.Fatalf("Unable to step: %s\n", err)
+	}
+	if gen.Num != 3 || life.Generations != 3 {
+		t.Fatalf("Stepped to generation %d instead of 3\n", gen.Num)
+	}
+
+	select {
+	case gen := <-listener:
+		t.Fatalf("Received generation %d while paused\n", gen.Num)
+	case <-time.After(time.Millisecond * 10):
+	}
+
+	life.Resume()
+	if gen := <-listener; gen.Num != 4 {
+		t.Fatalf("Received generation %d instead of 4\n", gen.Num)
+	}
+}
+
+func TestLifeStep(t *testing.T) {
+	life := newTestBlinkers(t, SimultaneousProcessor)
+
+	gen, err := life.Step(0)
+	if err != nil {
+		t.Fatalf("Unable to step: %s\n", err)
+	}
+	testLocationsMatch(t, life.Seed, gen.Living)
+
+	if gen, err = life.Step(2); err != nil {
+		t.Fatalf("Unable to step: %s\n", err)
+	}
+	testLocationsMatch(t, life.Seed, gen.Living)
+}
+
 func TestLifeGeneration(t *testing.T) {
 	dims := Dimensions{Height: 3, Width: 3}
 	strategy, err := New(
 		dims,
 		NeighborsAll,
+		TopologyPlane,
 		Blinkers,
 		ConwayTester(),
 		SimultaneousProcessor)
@@ -119,11 +230,343 @@ func TestLifeGeneration(t *testing.T) {
 	}
 }
 
+func TestLifeGenerationFromCurrent(t *testing.T) {
+	create := func() *Life {
+		life, err := New(
+			Dimensions{Height: 8, Width: 8},
+			NeighborsAll,
+			TopologyTorus,
+			Gliders,
+			ConwayTester(),
+			SimultaneousProcessor)
+		if err != nil {
+			t.Fatalf("Unable to create strategy: %s\n", err)
+		}
+		return life
+	}
+
+	life := create()
+	for i := 0; i < 4; i++ {
+		life.process()
+	}
+
+	// Earlier and later generations are both computed from the correct starting point
+	for _, num := range []int{2, 7} {
+		expected := create()
+		for i := 0; i < num; i++ {
+			expected.process()
+		}
+
+		testLocationsMatch(t, expected.pond.Living(), life.Generation(num).Living)
+	}
+}
+
+func TestLifeGenerationHashLife(t *testing.T) {
+	life, err := New(
+		Dimensions{Height: 4, Width: 4},
+		NeighborsAll,
+		TopologyUnbounded,
+		Gliders,
+		ConwayTester(),
+		NewHashLifeProcessor())
+	if err != nil {
+		t.Fatalf("Unable to create strategy: %s\n", err)
+	}
+
+	num := 1 << 40
+	gen := life.Generation(num)
+	if gen.Num != num {
+		t.Errorf("Retrieved %d generations instead of %d\n", gen.Num, num)
+	}
+
+	expected := make([]Location, len(life.Seed))
+	for i, loc := range life.Seed {
+		expected[i] = Location{X: loc.X + num/4, Y: loc.Y + num/4}
+	}
+	testLocationsMatch(t, expected, gen.Living)
+}
+
+func TestLifeHistory(t *testing.T) {
+	size := Dimensions{Height: 20, Width: 20}
+	seed := Random(size, Location{}, 35)
+	initializer := func(Dimensions, Location) []Location { return seed }
+
+	for _, processor := range []Processor{SimultaneousProcessor, BitboardProcessor} {
+		life, err := New(size, NeighborsAll, TopologyTorus, initializer, ConwayTester(), processor)
+		if err != nil {
+			t.Fatalf("Unable to create strategy: %s\n", err)
+		}
+		if err := life.SetHistoryPolicy(HistoryPolicy{SnapshotInterval: 16, MaxGenerations: 100}); err != nil {
+			t.Fatalf("Unable to set history policy: %s\n", err)
+		}
+
+		expected, err := newPond(size, newTracker(), NeighborsAll, TopologyTorus)
+		if err != nil {
+			t.Fatalf("Unable to create pond: %s\n", err)
+		}
+		expected.SetOrganisms(seed)
+
+		generations := [][]Location{expected.Living()}
+		for i := 0; i < 150; i++ {
+			if _, err := life.Step(1); err != nil {
+				t.Fatalf("Unable to step: %s\n", err)
+			}
+			SimultaneousProcessor.Process(expected, ConwayTester())
+			generations = append(generations, expected.Living())
+		}
+
+		// Both forgotten and remembered generations are retrieved
+		for num, living := range generations {
+			if num >= 50 || num%10 == 0 {
+				testLocationsMatch(t, living, life.Generation(num).Living)
+			}
+		}
+
+		// Only remembered generations can be sought
+		if _, err := life.Seek(10); err == nil {
+			t.Error("Sought a generation which was forgotten")
+		}
+
+		gen, err := life.Rewind(30)
+		if err != nil {
+			t.Fatalf("Unable to rewind: %s\n", err)
+		}
+		if gen.Num != 120 || life.Generations != 120 {
+			t.Fatalf("Rewound to generation %d instead of 120\n", gen.Num)
+		}
+		testLocationsMatch(t, generations[120], life.pond.Living())
+
+		if gen, err = life.Seek(140); err != nil {
+			t.Fatalf("Unable to seek: %s\n", err)
+		}
+		testLocationsMatch(t, generations[140], gen.Living)
+
+		// Processing continues on from the generation which was sought
+		if gen, err = life.Step(1); err != nil {
+			t.Fatalf("Unable to step: %s\n", err)
+		}
+		testLocationsMatch(t, generations[141], gen.Living)
+	}
+}
+
+// generationStates returns the state of every organism of the generation which is not dead
+func generationStates(gen *Generation) map[Location]int {
+	states := make(map[Location]int, len(gen.Living)+len(gen.Dying))
+	for _, loc := range gen.Living {
+		states[loc] = StateAlive
+	}
+	for loc, state := range gen.Dying {
+		states[loc] = state
+	}
+	return states
+}
+
+func TestLifeStates(t *testing.T) {
+	rules, err := ParseRules("345/2/4")
+	if err != nil {
+		t.Fatalf("Unable to parse rules: %s\n", err)
+	}
+
+	size := Dimensions{Height: 20, Width: 20}
+	seed := SeededRandom(size, Location{}, 35, 9)
+	initializer := func(Dimensions, Location) []Location { return seed }
+
+	life, err := New(size, NeighborsAll, TopologyTorus, initializer, RulesTester(rules), SimultaneousProcessor)
+	if err != nil {
+		t.Fatalf("Unable to create strategy: %s\n", err)
+	}
+	if err := life.SetStates(1); err == nil {
+		t.Fatal("Organisms were given a single state")
+	}
+	if err := life.SetStates(rules.States); err != nil {
+		t.Fatalf("Unable to set states: %s\n", err)
+	}
+
+	expected, err := newPond(size, newTracker(), NeighborsAll, TopologyTorus)
+	if err != nil {
+		t.Fatalf("Unable to create pond: %s\n", err)
+	}
+	expected.states = rules.States
+	expected.SetOrganisms(seed)
+
+	generations := []map[Location]int{pondStates(expected)}
+	for i := 0; i < 30; i++ {
+		gen, err := life.Step(1)
+		if err != nil {
+			t.Fatalf("Unable to step: %s\n", err)
+		}
+		SimultaneousProcessor.Process(expected, RulesTester(rules))
+		generations = append(generations, pondStates(expected))
+
+		// The generation reports the dying organisms along with the living
+		testStatesMatch(t, generations[gen.Num], generationStates(gen))
+	}
+
+	// Past generations are rebuilt from the history along with their dying organisms
+	for _, num := range []int{5, 22} {
+		testStatesMatch(t, generations[num], generationStates(life.Generation(num)))
+	}
+
+	// The current generation is not changed by processing the ones after it
+	current := life.Generation(life.Generations)
+	if _, err := life.Step(1); err != nil {
+		t.Fatalf("Unable to step: %s\n", err)
+	}
+	testStatesMatch(t, generations[30], generationStates(current))
+	generations = append(generations, pondStates(life.pond))
+
+	// Seeking restores the dying organisms, so processing continues as if it never left
+	if _, err := life.Seek(12); err != nil {
+		t.Fatalf("Unable to seek: %s\n", err)
+	}
+	testStatesMatch(t, generations[12], pondStates(life.pond))
+	if _, err := life.Step(1); err != nil {
+		t.Fatalf("Unable to step: %s\n", err)
+	}
+	testStatesMatch(t, generations[13], pondStates(life.pond))
+}
+
+func TestLifeNeighborhoodRules(t *testing.T) {
+	rules, err := ParseRules("B3/S2-i34q")
+	if err != nil {
+		t.Fatalf("Unable to parse rules: %s\n", err)
+	}
+
+	size := Dimensions{Height: 20, Width: 20}
+	seed := SeededRandom(size, Location{}, 35, 3)
+	initializer := func(Dimensions, Location) []Location { return seed }
+
+	processors := []Processor{SimultaneousProcessor, NewHashLifeProcessor(), ProcessorFunc(naiveProcessor)}
+	for _, processor := range processors {
+		life, err := New(size, NeighborsAll, TopologyUnbounded, initializer, RulesTester(rules), processor)
+		if err != nil {
+			t.Fatalf("Unable to create strategy: %s\n", err)
+		}
+		life.SetNeighborhoodRules(NeighborhoodTester(rules))
+
+		// Processors which cannot apply the rules hand the pond to one which can
+		expected, err := newPond(size, newTracker(), NeighborsAll, TopologyUnbounded)
+		if err != nil {
+			t.Fatalf("Unable to create pond: %s\n", err)
+		}
+		expected.SetOrganisms(seed)
+
+		generations := [][]Location{expected.Living()}
+		for i := 0; i < 20; i++ {
+			SimultaneousProcessor.ProcessNeighborhoods(expected, NeighborhoodTester(rules))
+			generations = append(generations, expected.Living())
+		}
+
+		gen, err := life.Step(20)
+		if err != nil {
+			t.Fatalf("Unable to step: %s\n", err)
+		}
+		testLocationsMatch(t, generations[20], gen.Living)
+
+		// Generations which are forgotten by the history are simulated with the same rules
+		if err := life.SetHistoryPolicy(DefaultHistoryPolicy); err != nil {
+			t.Fatalf("Unable to set history policy: %s\n", err)
+		}
+		testLocationsMatch(t, generations[7], life.Generation(7).Living)
+
+		// Without them the simulation follows the rules it was created with
+		life.SetNeighborhoodRules(nil)
+		SimultaneousProcessor.Process(expected, RulesTester(rules))
+		if gen, err = life.Step(1); err != nil {
+			t.Fatalf("Unable to step: %s\n", err)
+		}
+		testLocationsMatch(t, expected.Living(), gen.Living)
+	}
+}
+
+func TestLifeGenerationDeltas(t *testing.T) {
+	size := Dimensions{Height: 16, Width: 16}
+	seed := Random(size, Location{}, 35)
+	initializer := func(Dimensions, Location) []Location { return seed }
+
+	processors := []Processor{SimultaneousProcessor, ParallelProcessor, BitboardProcessor, NewHashLifeProcessor()}
+	for _, topology := range []Topology{TopologyTorus, TopologyUnbounded} {
+		for _, processor := range processors {
+			life, err := New(size, NeighborsAll, topology, initializer, ConwayTester(), processor)
+			if err != nil {
+				t.Fatalf("Unable to create strategy: %s\n", err)
+			}
+			life.SetGenerationContents(GenerationDeltas)
+
+			previous := make(map[Location]bool)
+			for _, loc := range seed {
+				previous[loc] = true
+			}
+
+			for i := 0; i < 10; i++ {
+				gen, err := life.Step(1)
+				if err != nil {
+					t.Fatalf("Unable to step: %s\n", err)
+				}
+				if gen.Living != nil {
+					t.Fatal("Generation contains the living organisms")
+				}
+
+				// Applying the deltas to the previous generation results in the current one
+				for _, loc := range gen.Died {
+					if !previous[loc] {
+						t.Fatalf("%s topology generation %d: organism at %v died but was not alive\n", topology, gen.Num, loc)
+					}
+					delete(previous, loc)
+				}
+				for _, loc := range gen.Born {
+					if previous[loc] {
+						t.Fatalf("%s topology generation %d: organism at %v was born but was already alive\n", topology, gen.Num, loc)
+					}
+					previous[loc] = true
+				}
+
+				current := make([]Location, 0, len(previous))
+				for loc := range previous {
+					current = append(current, loc)
+				}
+				testLocationsMatch(t, life.pond.Living(), current)
+			}
+		}
+	}
+}
+
+func TestLifeGenerationContents(t *testing.T) {
+	life := newTestBlinkers(t, SimultaneousProcessor)
+
+	gen, err := life.Step(1)
+	if err != nil {
+		t.Fatalf("Unable to step: %s\n", err)
+	}
+	if len(gen.Living) != 3 || gen.Born != nil || gen.Died != nil {
+		t.Fatalf("Generation has %d living, %d born and %d died organisms instead of only 3 living\n",
+			len(gen.Living), len(gen.Born), len(gen.Died))
+	}
+
+	life.SetGenerationContents(GenerationLivingAndDeltas)
+	if gen, err = life.Step(1); err != nil {
+		t.Fatalf("Unable to step: %s\n", err)
+	}
+	if len(gen.Living) != 3 || len(gen.Born) != 2 || len(gen.Died) != 2 {
+		t.Fatalf("Generation has %d living, %d born and %d died organisms instead of 3, 2 and 2\n",
+			len(gen.Living), len(gen.Born), len(gen.Died))
+	}
+
+	// Seeking reports the changes from the generation which was current
+	if gen, err = life.Seek(0); err != nil {
+		t.Fatalf("Unable to seek: %s\n", err)
+	}
+	if len(gen.Born) != 0 || len(gen.Died) != 0 {
+		t.Fatalf("Seeking to an identical generation had %d born and %d died organisms\n", len(gen.Born), len(gen.Died))
+	}
+}
+
 func TestLifeString(t *testing.T) {
 	dims := Dimensions{Height: 3, Width: 3}
 	strategy, err := New(
 		dims,
 		NeighborsAll,
+		TopologyPlane,
 		Blinkers,
 		ConwayTester(),
 		SimultaneousProcessor)
@@ -141,6 +584,7 @@ func TestLifeDimensions(t *testing.T) {
 	life, err := New(
 		dims,
 		NeighborsAll,
+		TopologyPlane,
 		Blinkers,
 		ConwayTester(),
 		SimultaneousProcessor)
@@ -155,4 +599,32 @@ func TestLifeDimensions(t *testing.T) {
 	}
 }
 
+func TestLifeDimensionsUnbounded(t *testing.T) {
+	dims := Dimensions{Height: 3, Width: 3}
+	life, err := New(
+		dims,
+		NeighborsAll,
+		TopologyUnbounded,
+		Blinkers,
+		ConwayTester(),
+		SimultaneousProcessor)
+	if err != nil {
+		t.Fatalf("Unable to create strategy: %s\n", err)
+	}
+
+	// A horizontal blinker
+	expected := Dimensions{Height: 1, Width: 3}
+	if retrievedDims := life.Dimensions(); !retrievedDims.Equals(&expected) {
+		t.Errorf("Retrieved dimensions %s instead of %s\n", retrievedDims.String(), expected.String())
+	}
+
+	life.process()
+
+	// A vertical blinker
+	expected = Dimensions{Height: 3, Width: 1}
+	if retrievedDims := life.Dimensions(); !retrievedDims.Equals(&expected) {
+		t.Errorf("Retrieved dimensions %s instead of %s\n", retrievedDims.String(), expected.String())
+	}
+}
+
 // vim: set foldmethod=marker:
@@ -0,0 +1,236 @@
+package life
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+const (
+	macrocellHeader    = "[M2]"
+	macrocellLeafLevel = 3 // Leaves are squares of 8x8 cells
+)
+
+func parseMacrocellLeaf(tree *quadtree, line string) (*quadNode, error) {
+	cells := make([]Location, 0)
+	size := 1 << macrocellLeafLevel
+
+	x, y := 0, 0
+	for _, c := range line {
+		switch c {
+		case '.':
+			x++
+		case '*':
+			if x >= size || y >= size {
+				return nil, fmt.Errorf("leaf is larger than %dx%d", size, size)
+			}
+			cells = append(cells, Location{X: x, Y: y})
+			x++
+		case '$':
+			x = 0
+			y++
+		default:
+			return nil, fmt.Errorf("unexpected character '%c' in leaf", c)
+		}
+	}
+
+	return tree.build(cells, macrocellLeafLevel, Location{}), nil
+}
+
+func parseMacrocellNode(tree *quadtree, nodes []*quadNode, line string) (*quadNode, error) {
+	fields := strings.Fields(line)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("node %q does not have a level and four quadrants", line)
+	}
+
+	vals := make([]int, len(fields))
+	for i, field := range fields {
+		val, err := strconv.Atoi(field)
+		if err != nil || val < 0 {
+			return nil, fmt.Errorf("invalid value %q in node", field)
+		}
+		vals[i] = val
+	}
+
+	level := uint(vals[0])
+	if level == 0 {
+		return nil, fmt.Errorf("node %q has an invalid level", line)
+	}
+
+	quadrants := make([]*quadNode, 4)
+	for i, val := range vals[1:] {
+		switch {
+		case level == 1:
+			// The quadrants of the smallest nodes are the cell states
+			quadrants[i] = tree.leaf(val != 0)
+		case val == 0:
+			quadrants[i] = tree.emptyNode(level - 1)
+		case val >= len(nodes):
+			return nil, fmt.Errorf("node %q references undefined node %d", line, val)
+		case nodes[val].level != level-1:
+			return nil, fmt.Errorf("node %q references node %d of level %d", line, val, nodes[val].level)
+		default:
+			quadrants[i] = nodes[val]
+		}
+	}
+
+	return tree.node(quadrants[0], quadrants[1], quadrants[2], quadrants[3]), nil
+}
+
+// ReadMacrocell decodes a pattern in Golly's macrocell (.mc) format.
+// The root node of the pattern is centered on the origin.
+func ReadMacrocell(r io.Reader) (*PatternFile, error) {
+	pattern := new(PatternFile)
+
+	tree := newQuadtree()
+	nodes := []*quadNode{nil} // Node numbering starts at 1
+
+	scanner := bufio.NewScanner(r)
+	for lineNum := 1; scanner.Scan(); lineNum++ {
+		line := strings.TrimSpace(scanner.Text())
+
+		switch {
+		case lineNum == 1:
+			if !strings.HasPrefix(line, macrocellHeader) {
+				return nil, fmt.Errorf("line %d: missing %s header", lineNum, macrocellHeader)
+			}
+			continue
+		case len(line) == 0:
+			continue
+		case strings.HasPrefix(line, "#"):
+			if len(line) < 2 {
+				continue
+			}
+			val := strings.TrimSpace(line[2:])
+			switch line[1] {
+			case 'N':
+				pattern.Name = val
+			case 'O':
+				pattern.Author = val
+			case 'C':
+				pattern.Comments = append(pattern.Comments, val)
+			case 'R':
+				rules, err := ParseRules(val)
+				if err != nil {
+					return nil, fmt.Errorf("line %d: %s", lineNum, err)
+				}
+				pattern.Rules = rules
+			}
+			continue
+		}
+
+		var n *quadNode
+		var err error
+		if line[0] >= '0' && line[0] <= '9' {
+			n, err = parseMacrocellNode(tree, nodes, line)
+		} else {
+			n, err = parseMacrocellLeaf(tree, line)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %s", lineNum, err)
+		}
+		nodes = append(nodes, n)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	pattern.Living = make([]Location, 0)
+	if len(nodes) > 1 {
+		root := nodes[len(nodes)-1]
+		half := root.size() / 2
+		pattern.Living = tree.locations(root, Location{X: -half, Y: -half})
+	}
+
+	_, pattern.Dims = BoundingBox(pattern.Living)
+
+	return pattern, nil
+}
+
+// macrocellWriter numbers the nodes as they are written so that repeated nodes are only written once
+type macrocellWriter struct {
+	tree    *quadtree
+	buf     bytes.Buffer
+	indices map[*quadNode]int
+}
+
+func (t *macrocellWriter) leaf(n *quadNode) string {
+	size := n.size()
+	rows := make([][]byte, size)
+	for y := range rows {
+		rows[y] = bytes.Repeat([]byte("."), size)
+	}
+	for _, loc := range t.tree.locations(n, Location{}) {
+		rows[loc.Y][loc.X] = '*'
+	}
+
+	var leaf bytes.Buffer
+	for _, row := range rows {
+		leaf.Write(bytes.TrimRight(row, "."))
+		leaf.WriteString("$")
+	}
+
+	// Trailing empty rows do not need to be written either
+	trimmed := strings.TrimRight(leaf.String(), "$")
+	return trimmed + "$"
+}
+
+func (t *macrocellWriter) write(n *quadNode) int {
+	if n.population == 0 {
+		return 0
+	}
+	if index, keyExists := t.indices[n]; keyExists {
+		return index
+	}
+
+	if n.level == macrocellLeafLevel {
+		t.buf.WriteString(t.leaf(n))
+	} else {
+		nw, ne, sw, se := t.write(n.nw), t.write(n.ne), t.write(n.sw), t.write(n.se)
+		t.buf.WriteString(fmt.Sprintf("%d %d %d %d %d", n.level, nw, ne, sw, se))
+	}
+	t.buf.WriteString("\n")
+
+	t.indices[n] = len(t.indices) + 1
+	return t.indices[n]
+}
+
+// WriteMacrocell encodes the given pattern in Golly's macrocell (.mc) format.
+// Repeated regions of the pattern are only written once, which keeps large patterns small.
+func WriteMacrocell(w io.Writer, pattern *PatternFile) error {
+	var buf bytes.Buffer
+
+	buf.WriteString(macrocellHeader + " (hokiegeek/life)\n")
+	if pattern.Rules != nil {
+		buf.WriteString("#R " + pattern.Rules.Rulestring() + "\n")
+	}
+	if len(pattern.Name) > 0 {
+		buf.WriteString("#N " + pattern.Name + "\n")
+	}
+	if len(pattern.Author) > 0 {
+		buf.WriteString("#O " + pattern.Author + "\n")
+	}
+	for _, comment := range pattern.Comments {
+		buf.WriteString("#C " + comment + "\n")
+	}
+
+	writer := &macrocellWriter{tree: newQuadtree(), indices: make(map[*quadNode]int)}
+
+	root, _ := writer.tree.fromLocations(pattern.Living, macrocellLeafLevel)
+	if root.population == 0 {
+		// An empty leaf, since an empty file would have no root
+		writer.buf.WriteString("$\n")
+	} else {
+		writer.write(root)
+	}
+	buf.Write(writer.buf.Bytes())
+
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+// vim: set foldmethod=marker:
@@ -0,0 +1,135 @@
+package life
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestReadMacrocell(t *testing.T) {
+	mc := `[M2] (golly 2.0)
+#R B3/S23
+.*$..*$***$
+4 0 0 0 1
+`
+
+	pattern, err := ReadMacrocell(strings.NewReader(mc))
+	if err != nil {
+		t.Fatalf("Unable to read macrocell: %s\n", err)
+	}
+
+	if pattern.Rules == nil || pattern.Rules.Rulestring() != "B3/S23" {
+		t.Error("Did not read the expected rules")
+	}
+
+	testLocationsMatch(t, Gliders(Dimensions{Width: 3, Height: 3}, Location{}), pattern.Living)
+}
+
+func TestReadMacrocellSmallNodes(t *testing.T) {
+	mc := `[M2]
+1 1 1 0 0
+2 1 0 1 0
+`
+
+	pattern, err := ReadMacrocell(strings.NewReader(mc))
+	if err != nil {
+		t.Fatalf("Unable to read macrocell: %s\n", err)
+	}
+
+	testLocationsMatch(t, []Location{
+		Location{X: -2, Y: -2}, Location{X: -1, Y: -2},
+		Location{X: -2, Y: 0}, Location{X: -1, Y: 0},
+	}, pattern.Living)
+}
+
+func TestReadMacrocellError(t *testing.T) {
+	bogus := []string{
+		"x = 3, y = 3\nbo$2bo$3o!",
+		"[M2]\n.*$..*$***$\n4 0 0 0 2\n",
+		"[M2]\n.*$..*$***$\n5 0 0 0 1\n",
+		"[M2]\n.*$..*$*x*$\n",
+		"[M2]\n.........*$\n",
+		"[M2]\n4 0 0 0\n",
+	}
+
+	for _, mc := range bogus {
+		if _, err := ReadMacrocell(strings.NewReader(mc)); err == nil {
+			t.Errorf("Unexpectedly read bogus macrocell %q\n", mc)
+		}
+	}
+}
+
+func TestWriteMacrocellSharing(t *testing.T) {
+	// A large grid of identical blocks should only need one leaf and one node per level
+	blocks := getRepeatingPattern(Dimensions{Width: 256, Height: 256}, Dimensions{Width: 8, Height: 8}, Location{X: 1, Y: 1},
+		func(seed *[]Location, currentX, currentY int) {
+			*seed = append(*seed, Blocks(Dimensions{Width: 4, Height: 4}, Location{X: currentX, Y: currentY})...)
+		})
+
+	if len(blocks) != 32*32*4 {
+		t.Fatalf("Generated %d cells instead of %d\n", len(blocks), 32*32*4)
+	}
+
+	var buf bytes.Buffer
+	if err := WriteMacrocell(&buf, &PatternFile{Living: blocks}); err != nil {
+		t.Fatalf("Unable to write macrocell: %s\n", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) > 8 {
+		t.Fatalf("Wrote %d lines for %d repeated blocks\n", len(lines), len(blocks)/4)
+	}
+
+	pattern, err := ReadMacrocell(&buf)
+	if err != nil {
+		t.Fatalf("Unable to read macrocell: %s\n", err)
+	}
+
+	testLocationsMatch(t, blocks, pattern.Living)
+}
+
+func TestMacrocellRoundTrip(t *testing.T) {
+	expected := &PatternFile{
+		Name:     "Soup",
+		Comments: []string{"A random soup"},
+		Rules:    &Rules{Survive: []int{2, 3}, Born: []int{3, 6}},
+		Living:   Random(Dimensions{Width: 50, Height: 30}, Location{X: -25, Y: 10}, 40),
+	}
+
+	var buf bytes.Buffer
+	if err := WriteMacrocell(&buf, expected); err != nil {
+		t.Fatalf("Unable to write macrocell: %s\n", err)
+	}
+
+	actual, err := ReadMacrocell(&buf)
+	if err != nil {
+		t.Fatalf("Unable to read macrocell: %s\n", err)
+	}
+
+	if actual.Name != expected.Name || len(actual.Comments) != 1 {
+		t.Error("Metadata did not survive the round trip")
+	}
+	if actual.Rules == nil || actual.Rules.Rulestring() != expected.Rules.Rulestring() {
+		t.Error("Rules did not survive the round trip")
+	}
+
+	testLocationsMatch(t, expected.Living, actual.Living)
+}
+
+func TestWriteMacrocellEmpty(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteMacrocell(&buf, &PatternFile{}); err != nil {
+		t.Fatalf("Unable to write macrocell: %s\n", err)
+	}
+
+	pattern, err := ReadMacrocell(&buf)
+	if err != nil {
+		t.Fatalf("Unable to read macrocell: %s\n", err)
+	}
+
+	if len(pattern.Living) != 0 {
+		t.Fatalf("Read %d living cells from an empty pattern\n", len(pattern.Living))
+	}
+}
+
+// vim: set foldmethod=marker:
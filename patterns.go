@@ -1,8 +1,8 @@
 package life
 
 import (
-	// "fmt"
-	"math/rand"
+	"fmt"
+	"strings"
 	"time"
 )
 
@@ -45,17 +45,29 @@ func getRepeatingPattern(boardDims, patternDims Dimensions, offset Location,
 	return seed
 }
 
-/////////////////// RANDOM ///////////////////
+// getRLEPattern repeats the pattern described by the given RLE encoded cells across the board,
+// with a spacer of one cell between each copy. The cells are only ever constants of this package,
+// so they are expected to parse and a mistake in one of them panics.
+func getRLEPattern(boardDims Dimensions, offset Location, cells string) []Location {
+	pattern, err := ReadRLE(strings.NewReader(cells))
+	if err != nil {
+		panic(fmt.Sprintf("invalid pattern %q: %s", cells, err))
+	}
+	_, patternDims := BoundingBox(pattern.Living)
 
-// Random generates a random pattern
-func Random(dimensions Dimensions, offset Location, percent int) []Location {
-	seed := make([]Location, 0)
+	// These patterns are rarely square, so each axis is tiled by its own size.
+	// The last copy on each axis does not need its spacer.
+	numPerRow := (boardDims.Width + 1) / (patternDims.Width + 1)
+	numPerCol := (boardDims.Height + 1) / (patternDims.Height + 1)
+
+	seed := make([]Location, 0, numPerRow*numPerCol*len(pattern.Living))
+	for row := 0; row < numPerCol; row++ {
+		currentY := (row * (patternDims.Height + 1)) + offset.Y
 
-	rand.Seed(time.Now().UnixNano())
-	for i := 0; i < dimensions.Height; i++ {
-		for j := 0; j < dimensions.Width; j++ {
-			if (100 - rand.Intn(100)) <= percent {
-				seed = append(seed, Location{X: j + offset.X, Y: i + offset.Y})
+		for col := 0; col < numPerRow; col++ {
+			currentX := (col * (patternDims.Width + 1)) + offset.X
+			for _, loc := range pattern.Living {
+				seed = append(seed, Location{X: currentX + loc.X, Y: currentY + loc.Y})
 			}
 		}
 	}
@@ -63,6 +75,13 @@ func Random(dimensions Dimensions, offset Location, percent int) []Location {
 	return seed
 }
 
+/////////////////// RANDOM ///////////////////
+
+// Random generates a random pattern which is different every time. Use SeededRandom or Soup for one which can be reproduced.
+func Random(dimensions Dimensions, offset Location, percent int) []Location {
+	return SeededRandom(dimensions, offset, percent, time.Now().UnixNano())
+}
+
 /////////////////// OSCILLATORS ///////////////////
 
 // Blinkers generates a basic Blinker oscillator
@@ -172,6 +191,26 @@ func Pulsar(dimensions Dimensions, offset Location) []Location {
 		})
 }
 
+// Pentadecathlon generates a Pentadecathlon oscillator, which has a period of 15
+//	--0----0--
+//	00-0000-00
+//	--0----0--
+func Pentadecathlon(dimensions Dimensions, offset Location) []Location {
+	return getRLEPattern(dimensions, offset, "2bo4bo2b$2ob4ob2o$2bo4bo2b!")
+}
+
+// QueenBeeShuttle generates a Queen Bee Shuttle oscillator, which has a period of 30
+//	---------0------------
+//	-------0-0------------
+//	------0-0-------------
+//	00---0--0-----------00
+//	00----0-0-----------00
+//	-------0-0------------
+//	---------0------------
+func QueenBeeShuttle(dimensions Dimensions, offset Location) []Location {
+	return getRLEPattern(dimensions, offset, "9bo12b$7bobo12b$6bobo13b$2o3bo2bo11b2o$2o4bobo11b2o$7bobo12b$9bo!")
+}
+
 /////////////////// SPACESHIPS ///////////////////
 
 // Gliders generates a basic Glider spaceship
@@ -199,6 +238,82 @@ func Gliders(dimensions Dimensions, offset Location) []Location {
 		})
 }
 
+// LightweightSpaceship generates a Lightweight Spaceship, which travels orthogonally at c/2
+//	-0--0
+//	0----
+//	0---0
+//	0000-
+func LightweightSpaceship(dimensions Dimensions, offset Location) []Location {
+	return getRLEPattern(dimensions, offset, "bo2bo$o4b$o3bo$4o!")
+}
+
+// MiddleweightSpaceship generates a Middleweight Spaceship, which travels orthogonally at c/2
+//	---0--
+//	-0---0
+//	0-----
+//	0----0
+//	00000-
+func MiddleweightSpaceship(dimensions Dimensions, offset Location) []Location {
+	return getRLEPattern(dimensions, offset, "3bo2b$bo3bo$o5b$o4bo$5o!")
+}
+
+// HeavyweightSpaceship generates a Heavyweight Spaceship, which travels orthogonally at c/2
+//	---00--
+//	-0----0
+//	0------
+//	0-----0
+//	000000-
+func HeavyweightSpaceship(dimensions Dimensions, offset Location) []Location {
+	return getRLEPattern(dimensions, offset, "3b2o2b$bo4bo$o6b$o5bo$6o!")
+}
+
+/////////////////// GUNS ///////////////////
+
+// GosperGliderGun generates the Gosper Glider Gun, which emits a glider every 30 generations
+func GosperGliderGun(dimensions Dimensions, offset Location) []Location {
+	return getRLEPattern(dimensions, offset,
+		"24bo$22bobo$12b2o6b2o12b2o$11bo3bo4b2o12b2o$2o8bo5bo3b2o$2o8bo3bob2o4bobo$10bo5bo7bo$11bo3bo$12b2o!")
+}
+
+// SimkinGliderGun generates the Simkin Glider Gun, which emits a glider every 120 generations
+func SimkinGliderGun(dimensions Dimensions, offset Location) []Location {
+	return getRLEPattern(dimensions, offset,
+		"2o5b2o$2o5b2o2$4b2o$4b2o5$22b2ob2o$21bo5bo$21bo6bo2b2o$21b3o3bo3b2o$26bo4$20b2o$20bo$21b3o$23bo!")
+}
+
+/////////////////// PUFFERS ///////////////////
+
+// PufferTrain generates a Puffer Train, which travels at c/2 while leaving a trail of debris behind it
+func PufferTrain(dimensions Dimensions, offset Location) []Location {
+	return getRLEPattern(dimensions, offset, "3bo$4bo$o3bo$b4o4$o$b2o$2bo$2bo$bo3$3bo$4bo$o3bo$b4o!")
+}
+
+/////////////////// METHUSELAHS ///////////////////
+
+// RPentomino generates the R-pentomino, which takes 1103 generations to stabilize
+//	-00
+//	00-
+//	-0-
+func RPentomino(dimensions Dimensions, offset Location) []Location {
+	return getRLEPattern(dimensions, offset, "b2o$2o$bo!")
+}
+
+// Acorn generates the Acorn, which takes 5206 generations to stabilize
+//	-0-----
+//	---0---
+//	00--000
+func Acorn(dimensions Dimensions, offset Location) []Location {
+	return getRLEPattern(dimensions, offset, "bo5b$3bo3b$2o2b3o!")
+}
+
+// Diehard generates the Diehard, which disappears after 130 generations
+//	------0-
+//	00------
+//	-0---000
+func Diehard(dimensions Dimensions, offset Location) []Location {
+	return getRLEPattern(dimensions, offset, "6bob$2o6b$bo3b3o!")
+}
+
 /////////////////// STILLS ///////////////////
 
 // Blocks generates the Block still pattern
@@ -133,4 +133,53 @@ func TestPatternOffsetting(t *testing.T) {
 	}
 }
 
+func TestRLEPatternInvalid(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Fatalf("Invalid pattern did not panic\n")
+		}
+	}()
+
+	getRLEPattern(Dimensions{Width: 10, Height: 10}, Location{}, "2o$2?!")
+}
+
+func TestRLEPatterns(t *testing.T) {
+	patterns := []struct {
+		name       string
+		pattern    func(Dimensions, Location) []Location
+		dims       Dimensions
+		population int
+	}{
+		{"pentadecathlon", Pentadecathlon, Dimensions{Width: 10, Height: 3}, 12},
+		{"queen bee shuttle", QueenBeeShuttle, Dimensions{Width: 22, Height: 7}, 20},
+		{"lwss", LightweightSpaceship, Dimensions{Width: 5, Height: 4}, 9},
+		{"mwss", MiddleweightSpaceship, Dimensions{Width: 6, Height: 5}, 11},
+		{"hwss", HeavyweightSpaceship, Dimensions{Width: 7, Height: 5}, 13},
+		{"gosper glider gun", GosperGliderGun, Dimensions{Width: 36, Height: 9}, 36},
+		{"simkin glider gun", SimkinGliderGun, Dimensions{Width: 33, Height: 21}, 36},
+		{"puffer train", PufferTrain, Dimensions{Width: 5, Height: 18}, 22},
+		{"r-pentomino", RPentomino, Dimensions{Width: 3, Height: 3}, 5},
+		{"acorn", Acorn, Dimensions{Width: 7, Height: 3}, 7},
+		{"diehard", Diehard, Dimensions{Width: 8, Height: 3}, 7},
+	}
+
+	for _, p := range patterns {
+		// A board the size of the pattern fits exactly one copy of it
+		single := p.pattern(p.dims, Location{X: 3, Y: 4})
+		if len(single) != p.population {
+			t.Fatalf("Pattern %s has %d organisms instead of %d\n", p.name, len(single), p.population)
+		}
+		origin, dims := BoundingBox(single)
+		if !dims.Equals(&p.dims) || origin != (Location{X: 3, Y: 4}) {
+			t.Fatalf("Pattern %s is %s at %s instead of %s at [3,4]\n", p.name, dims.String(), origin.String(), p.dims.String())
+		}
+
+		// Copies are repeated across the board with a spacer between them
+		tiled := p.pattern(Dimensions{Width: (p.dims.Width + 1) * 3, Height: (p.dims.Height + 1) * 2}, Location{})
+		if len(tiled) != p.population*6 {
+			t.Fatalf("Pattern %s has %d organisms when tiled instead of %d\n", p.name, len(tiled), p.population*6)
+		}
+	}
+}
+
 // vim: set foldmethod=marker:
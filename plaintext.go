@@ -0,0 +1,97 @@
+package life
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// ReadPlaintext decodes a pattern in the plaintext (.cells) format
+func ReadPlaintext(r io.Reader) (*PatternFile, error) {
+	pattern := &PatternFile{Living: make([]Location, 0)}
+
+	y := 0
+	inBody := false
+
+	scanner := bufio.NewScanner(r)
+	for lineNum := 1; scanner.Scan(); lineNum++ {
+		line := strings.TrimRight(scanner.Text(), " \t\r")
+
+		if strings.HasPrefix(line, "!") {
+			comment := strings.TrimSpace(line[1:])
+			switch {
+			case strings.HasPrefix(comment, "Name:"):
+				pattern.Name = strings.TrimSpace(comment[len("Name:"):])
+			case strings.HasPrefix(comment, "Author:"):
+				pattern.Author = strings.TrimSpace(comment[len("Author:"):])
+			default:
+				pattern.Comments = append(pattern.Comments, comment)
+			}
+			continue
+		}
+
+		// Blank lines are only empty rows once the pattern itself has started
+		if len(line) == 0 && !inBody {
+			continue
+		}
+		inBody = true
+
+		for x, c := range line {
+			switch c {
+			case '.':
+			case 'O', 'o', '*':
+				pattern.Living = append(pattern.Living, Location{X: x, Y: y})
+			default:
+				return nil, fmt.Errorf("line %d: unexpected character '%c' in pattern", lineNum, c)
+			}
+		}
+		y++
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	_, pattern.Dims = BoundingBox(pattern.Living)
+
+	return pattern, nil
+}
+
+// WritePlaintext encodes the given pattern in the plaintext (.cells) format
+func WritePlaintext(w io.Writer, pattern *PatternFile) error {
+	var buf bytes.Buffer
+
+	if len(pattern.Name) > 0 {
+		buf.WriteString("!Name: " + pattern.Name + "\n")
+	}
+	if len(pattern.Author) > 0 {
+		buf.WriteString("!Author: " + pattern.Author + "\n")
+	}
+	for _, comment := range pattern.Comments {
+		buf.WriteString("!" + comment + "\n")
+	}
+
+	origin, dims := BoundingBox(pattern.Living)
+	living := make(map[Location]bool, len(pattern.Living))
+	for _, loc := range pattern.Living {
+		living[loc] = true
+	}
+
+	for y := origin.Y; y < origin.Y+dims.Height; y++ {
+		for x := origin.X; x < origin.X+dims.Width; x++ {
+			if living[Location{X: x, Y: y}] {
+				buf.WriteString("O")
+			} else {
+				buf.WriteString(".")
+			}
+		}
+		buf.WriteString("\n")
+	}
+
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+// vim: set foldmethod=marker:
@@ -0,0 +1,80 @@
+package life
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestReadPlaintext(t *testing.T) {
+	cells := `!Name: Beehive
+!Author: John Conway
+!The most common seven-cell still life.
+.OO.
+O..O
+.OO.
+`
+
+	pattern, err := ReadPlaintext(strings.NewReader(cells))
+	if err != nil {
+		t.Fatalf("Unable to read plaintext: %s\n", err)
+	}
+
+	if pattern.Name != "Beehive" {
+		t.Errorf("Read name %q instead of Beehive\n", pattern.Name)
+	}
+	if pattern.Author != "John Conway" {
+		t.Errorf("Read author %q instead of John Conway\n", pattern.Author)
+	}
+	if len(pattern.Comments) != 1 {
+		t.Errorf("Read %d comments instead of 1\n", len(pattern.Comments))
+	}
+
+	testLocationsMatch(t, Beehive(Dimensions{Width: 4, Height: 4}, Location{}), pattern.Living)
+}
+
+func TestReadPlaintextEmptyRows(t *testing.T) {
+	cells := "O\n\nO\n"
+
+	pattern, err := ReadPlaintext(strings.NewReader(cells))
+	if err != nil {
+		t.Fatalf("Unable to read plaintext: %s\n", err)
+	}
+
+	testLocationsMatch(t, []Location{Location{X: 0, Y: 0}, Location{X: 0, Y: 2}}, pattern.Living)
+}
+
+func TestReadPlaintextError(t *testing.T) {
+	if _, err := ReadPlaintext(strings.NewReader(".O.\n.X.\n")); err == nil {
+		t.Error("Unexpectedly read bogus plaintext")
+	}
+}
+
+func TestPlaintextRoundTrip(t *testing.T) {
+	expected := &PatternFile{
+		Name:     "Pulsar",
+		Author:   "John Conway",
+		Comments: []string{"A period 3 oscillator"},
+		Living:   Pulsar(Dimensions{Width: 15, Height: 15}, Location{}),
+	}
+
+	var buf bytes.Buffer
+	if err := WritePlaintext(&buf, expected); err != nil {
+		t.Fatalf("Unable to write plaintext: %s\n", err)
+	}
+
+	actual, err := ReadPlaintext(&buf)
+	if err != nil {
+		t.Fatalf("Unable to read plaintext: %s\n", err)
+	}
+
+	if actual.Name != expected.Name || actual.Author != expected.Author || len(actual.Comments) != 1 {
+		t.Error("Metadata did not survive the round trip")
+	}
+
+	// The pattern is written from its bounding box
+	origin, _ := BoundingBox(expected.Living)
+	testLocationsMatch(t, Pulsar(Dimensions{Width: 15, Height: 15}, Location{X: -origin.X, Y: -origin.Y}), actual.Living)
+}
+
+// vim: set foldmethod=marker:
@@ -3,7 +3,9 @@ package life
 import (
 	"bytes"
 	"errors"
+	"fmt"
 	"strconv"
+	"strings"
 )
 
 // Location is a simple coordinate structure
@@ -86,71 +88,165 @@ func (t neighborsSelector) String() string {
 	return "Unknown"
 }
 
-type pond struct {
-	Dims              Dimensions
-	neighborsSelector neighborsSelector
-	living            *tracker
-}
-
-func (t *pond) getOrthogonalNeighbors(location Location) []Location {
-	neighbors := make([]Location, 0)
+// Topology describes the shape of the surface of the pond
+type Topology int
 
-	// Determine the offsets
-	left := location.X - 1
-	right := location.X + 1
-	above := location.Y - 1
-	below := location.Y + 1
+// Enumeration of the shapes the surface of the pond can take
+const (
+	TopologyPlane Topology = iota
+	TopologyTorus
+	TopologyUnbounded
+	TopologyKleinBottle
+	TopologyCrossSurface
+	TopologyCylinder
+)
 
-	if above >= 0 {
-		neighbors = append(neighbors, Location{X: location.X, Y: above})
+func (t Topology) String() string {
+	switch t {
+	case TopologyPlane:
+		return "Plane"
+	case TopologyTorus:
+		return "Torus"
+	case TopologyUnbounded:
+		return "Unbounded"
+	case TopologyKleinBottle:
+		return "KleinBottle"
+	case TopologyCrossSurface:
+		return "CrossSurface"
+	case TopologyCylinder:
+		return "Cylinder"
 	}
+	return "Unknown"
+}
 
-	if below < t.Dims.Height {
-		neighbors = append(neighbors, Location{X: location.X, Y: below})
+// ParseTopology returns the topology whose String() matches the given name
+func ParseTopology(name string) (Topology, error) {
+	for t := TopologyPlane; t <= TopologyCylinder; t++ {
+		if strings.EqualFold(t.String(), name) {
+			return t, nil
+		}
 	}
+	return TopologyPlane, fmt.Errorf("Did not recognize topology %q", name)
+}
 
-	if left >= 0 {
-		neighbors = append(neighbors, Location{X: left, Y: location.Y})
-	}
+type edgeBehavior int
 
-	if right < t.Dims.Width {
-		neighbors = append(neighbors, Location{X: right, Y: location.Y})
-	}
+// Enumeration of what happens when crossing an edge of the pond
+const (
+	edgeWall  edgeBehavior = iota // Nothing exists past the edge
+	edgeWrap                      // Continues from the opposite edge
+	edgeTwist                     // Continues from the opposite edge, mirrored
+	edgeNone                      // There is no edge
+)
 
-	// fmt.Printf("GetOrthogonalNeighbors(%s): %v\n", location.String(), neighbors)
-	return neighbors
+// edges returns the behavior of the horizontal (left and right) edges
+// and of the vertical (top and bottom) edges of the given topology
+func (t Topology) edges() (edgeBehavior, edgeBehavior) {
+	switch t {
+	case TopologyTorus:
+		return edgeWrap, edgeWrap
+	case TopologyUnbounded:
+		return edgeNone, edgeNone
+	case TopologyKleinBottle:
+		return edgeWrap, edgeTwist
+	case TopologyCrossSurface:
+		return edgeTwist, edgeTwist
+	case TopologyCylinder:
+		return edgeWrap, edgeWall
+	}
+	return edgeWall, edgeWall
 }
 
-func (t *pond) getObliqueNeighbors(location Location) []Location {
-	neighbors := make([]Location, 0)
+// Enumeration of the states of an organism. Under the rules of the Generations family
+// an organism which dies passes through the states which follow StateAlive before it is dead.
+const (
+	StateDead = iota
+	StateAlive
+)
 
-	// Determine the offsets
-	left := location.X - 1
-	right := location.X + 1
-	above := location.Y - 1
-	below := location.Y + 1
+// dyingGlyphs are drawn for the dying organisms, fading as they get closer to being dead
+var dyingGlyphs = []string{"o", "+", "-", "."}
 
-	if above >= 0 {
-		if left >= 0 {
-			neighbors = append(neighbors, Location{X: left, Y: above})
-		}
-		if right < t.Dims.Width {
-			neighbors = append(neighbors, Location{X: right, Y: above})
+// dyingGlyph returns the glyph which is drawn for an organism in the given dying state
+func dyingGlyph(state, states int) string {
+	return dyingGlyphs[(state-StateAlive-1)*len(dyingGlyphs)/(states-StateAlive-1)]
+}
+
+type pond struct {
+	Dims              Dimensions
+	neighborsSelector neighborsSelector
+	topology          Topology
+	living            cellStore
+	states            int              // The number of states an organism can be in, including alive and dead
+	dying             map[Location]int // The states of the organisms which are dying
+	changes           *changeSet       // Records the organisms whose state is changed, when not nil
+}
+
+// resolveLocation maps the given location onto the pond's surface.
+// Returns false if the location falls off of the edge of the pond.
+func (t *pond) resolveLocation(location Location) (Location, bool) {
+	horizontal, vertical := t.topology.edges()
+
+	if location.X < 0 || location.X >= t.Dims.Width {
+		switch horizontal {
+		case edgeWall:
+			return location, false
+		case edgeWrap:
+			location.X = wrapCoordinate(location.X, t.Dims.Width)
+		case edgeTwist:
+			location.X = wrapCoordinate(location.X, t.Dims.Width)
+			location.Y = t.Dims.Height - 1 - location.Y
 		}
 	}
 
-	if below < t.Dims.Height {
-		if left >= 0 {
-			neighbors = append(neighbors, Location{X: left, Y: below})
+	if location.Y < 0 || location.Y >= t.Dims.Height {
+		switch vertical {
+		case edgeWall:
+			return location, false
+		case edgeWrap:
+			location.Y = wrapCoordinate(location.Y, t.Dims.Height)
+		case edgeTwist:
+			location.Y = wrapCoordinate(location.Y, t.Dims.Height)
+			location.X = t.Dims.Width - 1 - location.X
 		}
-		if right < t.Dims.Width {
-			neighbors = append(neighbors, Location{X: right, Y: below})
+	}
+
+	return location, true
+}
+
+func wrapCoordinate(val, size int) int {
+	val %= size
+	if val < 0 {
+		val += size
+	}
+	return val
+}
+
+func (t *pond) getNeighborsAtOffsets(location Location, offsets []Location) []Location {
+	neighbors := make([]Location, 0)
+
+	for _, offset := range offsets {
+		if neighbor, valid := t.resolveLocation(Location{X: location.X + offset.X, Y: location.Y + offset.Y}); valid {
+			neighbors = append(neighbors, neighbor)
 		}
 	}
 
 	return neighbors
 }
 
+var (
+	orthogonalOffsets = []Location{{X: 0, Y: -1}, {X: 0, Y: 1}, {X: -1, Y: 0}, {X: 1, Y: 0}}
+	obliqueOffsets    = []Location{{X: -1, Y: -1}, {X: 1, Y: -1}, {X: -1, Y: 1}, {X: 1, Y: 1}}
+)
+
+func (t *pond) getOrthogonalNeighbors(location Location) []Location {
+	return t.getNeighborsAtOffsets(location, orthogonalOffsets)
+}
+
+func (t *pond) getObliqueNeighbors(location Location) []Location {
+	return t.getNeighborsAtOffsets(location, obliqueOffsets)
+}
+
 func (t *pond) getAllNeighbors(location Location) []Location {
 	neighbors := append(t.getOrthogonalNeighbors(location), t.getObliqueNeighbors(location)...)
 
@@ -174,7 +270,33 @@ func (t *pond) GetNeighbors(organism Location) ([]Location, error) {
 	return nil, errors.New("Did not recognize neighbor selector")
 }
 
+// GetNeighborhood returns the neighbors of the given location along with the direction each of them is in
+func (t *pond) GetNeighborhood(organism Location) ([]Location, []Neighborhood, error) {
+	if !t.isValidLocation(organism) {
+		return nil, nil, errors.New("Location is out of bounds")
+	}
+
+	neighbors := make([]Location, 0, len(neighborhoodOffsets))
+	directions := make([]Neighborhood, 0, len(neighborhoodOffsets))
+	for i, offset := range neighborhoodOffsets {
+		direction := Neighborhood(1 << uint(i))
+		if !t.neighborsSelector.selects(direction) {
+			continue
+		}
+
+		if neighbor, valid := t.resolveLocation(Location{X: organism.X + offset.X, Y: organism.Y + offset.Y}); valid {
+			neighbors = append(neighbors, neighbor)
+			directions = append(directions, direction)
+		}
+	}
+
+	return neighbors, directions, nil
+}
+
 func (t *pond) isValidLocation(location Location) bool {
+	if t.topology == TopologyUnbounded {
+		return true
+	}
 	if location.X < 0 || location.X > t.Dims.Width {
 		return false
 	}
@@ -184,22 +306,80 @@ func (t *pond) isValidLocation(location Location) bool {
 	return true
 }
 
-func (t *pond) isOrganismAlive(organism Location) bool {
+// Bounds returns the origin and size of the area of the pond which is in use.
+// For an unbounded pond this is the bounding box of the living and dying organisms.
+func (t *pond) Bounds() (Location, Dimensions) {
+	if t.topology != TopologyUnbounded {
+		return Location{}, t.Dims
+	}
+
+	return BoundingBox(append(t.living.GetAll(), t.Dying()...))
+}
+
+// Topology returns the shape of the pond's surface
+func (t *pond) Topology() Topology {
+	return t.topology
+}
+
+// Living returns the locations of all of the living organisms
+func (t *pond) Living() []Location {
+	return t.living.GetAll()
+}
+
+// IsAlive tests if the organism at the given location is alive
+func (t *pond) IsAlive(organism Location) bool {
 	// return (t.GetOrganismValue(organism) >= 0)
 	return t.living.Test(organism)
 }
 
-func (t *pond) setOrganismState(organism Location, alive bool) {
+// States returns the number of states an organism can be in, including alive and dead
+func (t *pond) States() int {
+	return t.states
+}
+
+// State returns the state of the organism at the given location
+func (t *pond) State(organism Location) int {
+	if t.IsAlive(organism) {
+		return StateAlive
+	}
+	return t.dying[organism]
+}
+
+// SetState sets the state of the organism at the given location
+func (t *pond) SetState(organism Location, state int) {
+	t.SetAlive(organism, state == StateAlive)
+	if state > StateAlive {
+		t.dying[organism] = state
+	} else {
+		delete(t.dying, organism)
+	}
+}
+
+// Dying returns the locations of all of the organisms which are dying
+func (t *pond) Dying() []Location {
+	dying := make([]Location, 0, len(t.dying))
+	for loc := range t.dying {
+		dying = append(dying, loc)
+	}
+	return dying
+}
+
+// SetAlive sets the state of the organism at the given location
+func (t *pond) SetAlive(organism Location, alive bool) {
 	// fmt.Printf("\tsetNeighborCount(%s, %d)\n", organism.String(), num)
-	originalState := t.isOrganismAlive(organism)
+	originalState := t.IsAlive(organism)
 
 	// Only do the deed if something has changed TODO: is this a stupid optimization?
 	if originalState != alive {
 		if alive {
 			t.living.Set(organism)
+			delete(t.dying, organism)
 		} else {
 			t.living.Remove(organism)
 		}
+		if t.changes != nil {
+			t.changes.record(organism, alive)
+		}
 		// fmt.Printf("Living count is: %d\n", t.living.Count())
 	}
 }
@@ -207,17 +387,21 @@ func (t *pond) setOrganismState(organism Location, alive bool) {
 func (t *pond) SetOrganisms(organisms []Location) {
 	// Initialize the first organisms and set their neighbor counts
 	for _, organism := range organisms {
-		t.setOrganismState(organism, true)
+		t.SetAlive(organism, true)
 	}
 }
 
 func (t *pond) Clone() (*pond, error) {
-	shadowpond, err := newPond(t.Dims, t.living.Clone(), t.neighborsSelector)
+	shadowpond, err := newPond(t.Dims, t.living.Clone(), t.neighborsSelector, t.topology)
 	if err != nil {
 		return nil, err
 	}
 
 	shadowpond.neighborsSelector = t.neighborsSelector
+	shadowpond.states = t.states
+	for loc, state := range t.dying {
+		shadowpond.dying[loc] = state
+	}
 
 	shadowpond.SetOrganisms(t.living.GetAll())
 
@@ -231,6 +415,17 @@ func (t *pond) Equals(rhs *pond) bool {
 	if t.neighborsSelector != rhs.neighborsSelector {
 		return false
 	}
+	if t.topology != rhs.topology {
+		return false
+	}
+	if t.states != rhs.states || len(t.dying) != len(rhs.dying) {
+		return false
+	}
+	for loc, state := range t.dying {
+		if rhs.dying[loc] != state {
+			return false
+		}
+	}
 	return true
 }
 
@@ -238,29 +433,45 @@ func (t *pond) String() string {
 	var buf bytes.Buffer
 	buf.WriteString("Neighbors: ")
 	buf.WriteString(t.neighborsSelector.String())
+	buf.WriteString("\tTopology: ")
+	buf.WriteString(t.topology.String())
 	buf.WriteString("\tLiving cells: ")
 	buf.WriteString(strconv.Itoa(t.living.Count()))
+	if t.states > 2 {
+		buf.WriteString("\tStates: ")
+		buf.WriteString(strconv.Itoa(t.states))
+		buf.WriteString("\tDying cells: ")
+		buf.WriteString(strconv.Itoa(len(t.dying)))
+	}
 	buf.WriteString("\n")
 
 	//// DRAW THE BOARD ////
+	origin, dims := t.Bounds()
 	buf.WriteString("Size: ")
-	buf.WriteString(t.Dims.String())
+	buf.WriteString(dims.String())
+	if t.topology == TopologyUnbounded {
+		buf.WriteString("\tOrigin: ")
+		buf.WriteString(origin.String())
+	}
 	buf.WriteString("\n")
 
 	// Draw the top border
 	buf.WriteString("┌")
-	for j := t.Dims.Width; j > 0; j-- {
+	for j := dims.Width; j > 0; j-- {
 		buf.WriteString("─")
 	}
 	buf.WriteString("┐\n")
 
 	// Draw out the matrix
-	for y := 0; y < t.Dims.Height; y++ {
+	for y := origin.Y; y < origin.Y+dims.Height; y++ {
 		buf.WriteString("│") // Left border
-		for x := 0; x < t.Dims.Width; x++ {
-			if t.isOrganismAlive(Location{X: x, Y: y}) {
+		for x := origin.X; x < origin.X+dims.Width; x++ {
+			switch state := t.State(Location{X: x, Y: y}); {
+			case state == StateAlive:
 				buf.WriteString("0")
-			} else {
+			case state > StateAlive:
+				buf.WriteString(dyingGlyph(state, t.states))
+			default:
 				buf.WriteString(" ")
 			}
 		}
@@ -269,7 +480,7 @@ func (t *pond) String() string {
 
 	// Draw the bottom border
 	buf.WriteString("└")
-	for j := t.Dims.Width; j > 0; j-- {
+	for j := dims.Width; j > 0; j-- {
 		buf.WriteString("─")
 	}
 	buf.WriteString("┘\n")
@@ -277,19 +488,22 @@ func (t *pond) String() string {
 	return buf.String()
 }
 
-func newPond(dims Dimensions, tracker *tracker, neighbors neighborsSelector) (*pond, error) {
-	if dims.Capacity() == 0 {
+func newPond(dims Dimensions, living cellStore, neighbors neighborsSelector, topology Topology) (*pond, error) {
+	if dims.Capacity() == 0 && topology != TopologyUnbounded {
 		return nil, errors.New("Cannot create pond of zero capacity")
 	}
 
 	p := new(pond)
 
-	if tracker == nil {
+	if living == nil {
 		return nil, errors.New("tracker cannot be nil")
 	}
 
-	p.living = tracker
+	p.living = living
+	p.states = 2
+	p.dying = make(map[Location]int)
 	p.neighborsSelector = neighbors
+	p.topology = topology
 
 	p.Dims = dims
 
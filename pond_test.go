@@ -1,6 +1,9 @@
 package life
 
-import "testing"
+import (
+	"strings"
+	"testing"
+)
 
 func TestLocationString(t *testing.T) {
 	loc := Location{X: 1, Y: 1}
@@ -62,16 +65,70 @@ func TestNeighborSelectorString(t *testing.T) {
 	}
 }
 
+func TestTopologyString(t *testing.T) {
+	var topo Topology
+
+	topo = TopologyPlane
+	if topo.String() != "Plane" {
+		t.Error("Unexpectedly retrieved wrong string from topology object")
+	}
+
+	topo = TopologyTorus
+	if topo.String() != "Torus" {
+		t.Error("Unexpectedly retrieved wrong string from topology object")
+	}
+
+	topo = TopologyUnbounded
+	if topo.String() != "Unbounded" {
+		t.Error("Unexpectedly retrieved wrong string from topology object")
+	}
+
+	topo = TopologyKleinBottle
+	if topo.String() != "KleinBottle" {
+		t.Error("Unexpectedly retrieved wrong string from topology object")
+	}
+
+	topo = TopologyCrossSurface
+	if topo.String() != "CrossSurface" {
+		t.Error("Unexpectedly retrieved wrong string from topology object")
+	}
+
+	topo = TopologyCylinder
+	if topo.String() != "Cylinder" {
+		t.Error("Unexpectedly retrieved wrong string from topology object")
+	}
+}
+
+func TestParseTopology(t *testing.T) {
+	for topo := TopologyPlane; topo <= TopologyCylinder; topo++ {
+		parsed, err := ParseTopology(topo.String())
+		if err != nil {
+			t.Fatalf("Unable to parse topology %s: %s\n", topo.String(), err)
+		}
+		if parsed != topo {
+			t.Errorf("Parsed %s as %s\n", topo.String(), parsed.String())
+		}
+	}
+
+	if parsed, err := ParseTopology("klEINbottle"); err != nil || parsed != TopologyKleinBottle {
+		t.Error("Unable to parse topology name in a different case")
+	}
+
+	if _, err := ParseTopology("moebius"); err == nil {
+		t.Error("Unexpectedly parsed an unknown topology")
+	}
+}
+
 func TestCreateCreateError(t *testing.T) {
 	size := Dimensions{Width: 0, Height: 0}
-	_, err := newPond(size, newTracker(), NeighborsAll)
+	_, err := newPond(size, newTracker(), NeighborsAll, TopologyPlane)
 	if err == nil {
 		t.Fatal("Unexpectedly successful at creating pond of 0 capacity")
 	}
 }
 
 func TestPondSettingInitialPatterns(t *testing.T) {
-	pond, err := newPond(Dimensions{Height: 3, Width: 3}, newTracker(), NeighborsAll)
+	pond, err := newPond(Dimensions{Height: 3, Width: 3}, newTracker(), NeighborsAll, TopologyPlane)
 	if err != nil {
 		t.Fatal("Unable to create pond")
 	}
@@ -86,14 +143,14 @@ func TestPondSettingInitialPatterns(t *testing.T) {
 
 	// Check each expected value
 	for _, loc := range initialLiving {
-		if !pond.isOrganismAlive(loc) {
+		if !pond.IsAlive(loc) {
 			t.Fatalf("Seed organism is not alive!: %s\n", loc.String())
 		}
 	}
 }
 
 func TestPondNeighborSelectionOrthogonal(t *testing.T) {
-	pond, err := newPond(Dimensions{Height: 3, Width: 3}, newTracker(), NeighborsOrthogonal)
+	pond, err := newPond(Dimensions{Height: 3, Width: 3}, newTracker(), NeighborsOrthogonal, TopologyPlane)
 	if err != nil {
 		t.Fatalf("Unable to create pond: %s\n", err)
 	}
@@ -116,7 +173,7 @@ func TestPondNeighborSelectionOrthogonal(t *testing.T) {
 }
 
 func TestPondNeighborSelectionOblique(t *testing.T) {
-	pond, err := newPond(Dimensions{Height: 3, Width: 3}, newTracker(), NeighborsOblique)
+	pond, err := newPond(Dimensions{Height: 3, Width: 3}, newTracker(), NeighborsOblique, TopologyPlane)
 	if err != nil {
 		t.Fatalf("Unable to create pond: %s\n", err)
 	}
@@ -138,8 +195,152 @@ func TestPondNeighborSelectionOblique(t *testing.T) {
 	}
 }
 
+func TestPondNeighborSelectionTorus(t *testing.T) {
+	pond, err := newPond(Dimensions{Height: 4, Width: 4}, newTracker(), NeighborsAll, TopologyTorus)
+	if err != nil {
+		t.Fatalf("Unable to create pond: %s\n", err)
+	}
+
+	expected := []Location{
+		Location{X: 3, Y: 3}, Location{X: 0, Y: 3}, Location{X: 1, Y: 3},
+		Location{X: 3, Y: 0}, Location{X: 1, Y: 0},
+		Location{X: 3, Y: 1}, Location{X: 0, Y: 1}, Location{X: 1, Y: 1},
+	}
+
+	actual, err := pond.GetNeighbors(Location{X: 0, Y: 0})
+	if err != nil {
+		t.Fatalf("Unable to retrieve neighbors: %s\n", err)
+	}
+
+	if len(actual) != len(expected) {
+		t.Fatalf("Retrieved %d neighbors but expected %d\n", len(actual), len(expected))
+	}
+
+	for _, expectedLoc := range expected {
+		found := false
+		for _, actualLoc := range actual {
+			if expectedLoc.Equals(&actualLoc) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			t.Fatalf("Did not find neighbor %s in actual list\n", expectedLoc.String())
+		}
+	}
+}
+
+func TestPondNeighborhood(t *testing.T) {
+	pond, err := newPond(Dimensions{Height: 4, Width: 4}, newTracker(), NeighborsAll, TopologyCylinder)
+	if err != nil {
+		t.Fatalf("Unable to create pond: %s\n", err)
+	}
+
+	// The neighbors above the top edge do not exist, the ones to the left wrap around to the right edge
+	expected := map[Neighborhood]Location{
+		NeighborW: {X: 3, Y: 0}, NeighborE: {X: 1, Y: 0},
+		NeighborSW: {X: 3, Y: 1}, NeighborS: {X: 0, Y: 1}, NeighborSE: {X: 1, Y: 1},
+	}
+
+	neighbors, directions, err := pond.GetNeighborhood(Location{X: 0, Y: 0})
+	if err != nil {
+		t.Fatalf("Unable to retrieve neighborhood: %s\n", err)
+	}
+	if len(neighbors) != len(expected) || len(directions) != len(expected) {
+		t.Fatalf("Retrieved %d neighbors and %d directions but expected %d\n", len(neighbors), len(directions), len(expected))
+	}
+	for i, direction := range directions {
+		if loc, found := expected[direction]; !found || !loc.Equals(&neighbors[i]) {
+			t.Fatalf("Neighbor %s is in direction %08b\n", neighbors[i].String(), direction)
+		}
+	}
+
+	pond.neighborsSelector = NeighborsOblique
+	if _, directions, err = pond.GetNeighborhood(Location{X: 1, Y: 1}); err != nil {
+		t.Fatalf("Unable to retrieve neighborhood: %s\n", err)
+	}
+	for _, direction := range directions {
+		if direction&orthogonalNeighborhood != 0 {
+			t.Fatalf("Oblique neighborhood has the orthogonal direction %08b\n", direction)
+		}
+	}
+}
+
+func TestPondResolveLocation(t *testing.T) {
+	tests := []struct {
+		topology Topology
+		location Location
+		expected Location
+		valid    bool
+	}{
+		{TopologyPlane, Location{X: -1, Y: 0}, Location{}, false},
+		{TopologyTorus, Location{X: -1, Y: 5}, Location{X: 4, Y: 0}, true},
+		{TopologyCylinder, Location{X: -1, Y: 0}, Location{X: 4, Y: 0}, true},
+		{TopologyCylinder, Location{X: 0, Y: -1}, Location{}, false},
+		{TopologyKleinBottle, Location{X: 5, Y: 1}, Location{X: 0, Y: 1}, true},
+		{TopologyKleinBottle, Location{X: 1, Y: -1}, Location{X: 3, Y: 4}, true},
+		{TopologyCrossSurface, Location{X: -1, Y: 1}, Location{X: 4, Y: 3}, true},
+		{TopologyCrossSurface, Location{X: 1, Y: 5}, Location{X: 3, Y: 0}, true},
+		{TopologyUnbounded, Location{X: -42, Y: 42}, Location{X: -42, Y: 42}, true},
+	}
+
+	for _, test := range tests {
+		pond, err := newPond(Dimensions{Height: 5, Width: 5}, newTracker(), NeighborsAll, test.topology)
+		if err != nil {
+			t.Fatalf("Unable to create pond: %s\n", err)
+		}
+
+		actual, valid := pond.resolveLocation(test.location)
+		if valid != test.valid {
+			t.Errorf("%s: location %s resolved as valid=%t\n", test.topology.String(), test.location.String(), valid)
+			continue
+		}
+		if valid && !actual.Equals(&test.expected) {
+			t.Errorf("%s: location %s resolved to %s instead of %s\n",
+				test.topology.String(), test.location.String(), actual.String(), test.expected.String())
+		}
+	}
+}
+
+func TestPondNeighborSelectionUnbounded(t *testing.T) {
+	pond, err := newPond(Dimensions{Height: 1, Width: 1}, newTracker(), NeighborsAll, TopologyUnbounded)
+	if err != nil {
+		t.Fatalf("Unable to create pond: %s\n", err)
+	}
+
+	actual, err := pond.GetNeighbors(Location{X: -5, Y: 42})
+	if err != nil {
+		t.Fatalf("Unable to retrieve neighbors: %s\n", err)
+	}
+
+	if len(actual) != 8 {
+		t.Fatalf("Retrieved %d neighbors but expected 8\n", len(actual))
+	}
+}
+
+func TestPondBoundsUnbounded(t *testing.T) {
+	pond, err := newPond(Dimensions{}, newTracker(), NeighborsAll, TopologyUnbounded)
+	if err != nil {
+		t.Fatalf("Unable to create pond: %s\n", err)
+	}
+
+	pond.SetOrganisms([]Location{Location{X: -3, Y: 2}, Location{X: 4, Y: -1}, Location{X: 0, Y: 0}})
+
+	origin, dims := pond.Bounds()
+
+	expectedOrigin := Location{X: -3, Y: -1}
+	if !origin.Equals(&expectedOrigin) {
+		t.Errorf("Retrieved origin %s instead of %s\n", origin.String(), expectedOrigin.String())
+	}
+
+	expectedDims := Dimensions{Width: 8, Height: 4}
+	if !dims.Equals(&expectedDims) {
+		t.Errorf("Retrieved dimensions %s instead of %s\n", dims.String(), expectedDims.String())
+	}
+}
+
 func TestPondNeighborSelectionError(t *testing.T) {
-	pond, err := newPond(Dimensions{Height: 1, Width: 1}, newTracker(), NeighborsAll)
+	pond, err := newPond(Dimensions{Height: 1, Width: 1}, newTracker(), NeighborsAll, TopologyPlane)
 	if err != nil {
 		t.Fatalf("Unable to create pond: %s\n", err)
 	}
@@ -154,7 +355,7 @@ func TestPondNeighborSelectionError(t *testing.T) {
 func TestPondOrganismValue(t *testing.T) {
 	expectedVal := 2
 	pos := Location{X: 0, Y: 0}
-	pond, err := newPond(Dimensions{Height: 1, Width: 1}, newTracker(), NeighborsAll)
+	pond, err := newPond(Dimensions{Height: 1, Width: 1}, newTracker(), NeighborsAll, TopologyPlane)
 	if err != nil {
 		t.Fatal("Unable to create pond")
 	}
@@ -168,9 +369,27 @@ func TestPondOrganismValue(t *testing.T) {
 }
 */
 
+func TestPondRecordChanges(t *testing.T) {
+	pond, err := newPond(Dimensions{Height: 3, Width: 3}, newTracker(), NeighborsAll, TopologyPlane)
+	if err != nil {
+		t.Fatal("Unable to create pond")
+	}
+	pond.SetAlive(Location{X: 0, Y: 0}, true)
+
+	pond.changes = new(changeSet)
+	pond.SetAlive(Location{X: 1, Y: 1}, true)
+	pond.SetAlive(Location{X: 0, Y: 0}, false)
+	// Neither of these change anything
+	pond.SetAlive(Location{X: 1, Y: 1}, true)
+	pond.SetAlive(Location{X: 2, Y: 2}, false)
+
+	testLocationsMatch(t, []Location{{X: 1, Y: 1}}, pond.changes.born)
+	testLocationsMatch(t, []Location{{X: 0, Y: 0}}, pond.changes.died)
+}
+
 func TestPondString(t *testing.T) {
 	dims := Dimensions{Height: 3, Width: 3}
-	pond, err := newPond(dims, newTracker(), NeighborsAll)
+	pond, err := newPond(dims, newTracker(), NeighborsAll, TopologyPlane)
 	if err != nil {
 		t.Fatal("Unable to create pond")
 	}
@@ -179,10 +398,55 @@ func TestPondString(t *testing.T) {
 	}
 }
 
+func TestPondStates(t *testing.T) {
+	pond, err := newPond(Dimensions{Height: 3, Width: 4}, newTracker(), NeighborsAll, TopologyPlane)
+	if err != nil {
+		t.Fatal("Unable to create pond")
+	}
+	if pond.States() != 2 {
+		t.Fatalf("Organisms of a new pond have %d states instead of 2\n", pond.States())
+	}
+	pond.states = 5
+
+	pond.SetState(Location{X: 0, Y: 0}, StateAlive)
+	pond.SetState(Location{X: 1, Y: 1}, 2)
+	pond.SetState(Location{X: 2, Y: 1}, 4)
+	if pond.State(Location{X: 0, Y: 0}) != StateAlive || !pond.IsAlive(Location{X: 0, Y: 0}) {
+		t.Fatal("Organism was not set alive")
+	}
+	if pond.State(Location{X: 1, Y: 1}) != 2 || pond.IsAlive(Location{X: 1, Y: 1}) {
+		t.Fatal("Organism was not set dying")
+	}
+	if pond.State(Location{X: 3, Y: 2}) != StateDead {
+		t.Fatal("Organism which was never set is not dead")
+	}
+	testLocationsMatch(t, []Location{{X: 1, Y: 1}, {X: 2, Y: 1}}, pond.Dying())
+
+	expected := "│0   │\n│ o- │\n"
+	if actual := pond.String(); !strings.Contains(actual, expected) {
+		t.Fatalf("Pond was drawn as\n%s\ninstead of containing\n%s\n", actual, expected)
+	}
+
+	clone, err := pond.Clone()
+	if err != nil {
+		t.Fatalf("Unable to clone pond: %s\n", err)
+	}
+	if clone.States() != 5 || clone.State(Location{X: 2, Y: 1}) != 4 {
+		t.Fatal("Clone did not keep the dying organisms")
+	}
+
+	// Coming alive or dying again clears the dying state
+	pond.SetAlive(Location{X: 1, Y: 1}, true)
+	pond.SetState(Location{X: 2, Y: 1}, StateDead)
+	if len(pond.Dying()) != 0 {
+		t.Fatalf("Pond still has %d dying organisms\n", len(pond.Dying()))
+	}
+}
+
 func TestPondEquals(t *testing.T) {
 	t.Skip("whoops")
 	dims := Dimensions{Height: 3, Width: 3}
-	pond, err := newPond(dims, newTracker(), NeighborsAll)
+	pond, err := newPond(dims, newTracker(), NeighborsAll, TopologyPlane)
 	if err != nil {
 		t.Fatal("Unable to create pond")
 	}
@@ -191,7 +455,7 @@ func TestPondEquals(t *testing.T) {
 		t.Fatal("Pond Equals failed identity test")
 	}
 
-	pond2, err := newPond(dims, newTracker(), NeighborsAll)
+	pond2, err := newPond(dims, newTracker(), NeighborsAll, TopologyPlane)
 	if err != nil {
 		t.Fatal("Unable to create pond")
 	}
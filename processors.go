@@ -1,17 +1,245 @@
 package life
 
-// SimultaneousProcessor simultaneously applies the given rules to the given pond. This is the default Conway processor.
-func SimultaneousProcessor(pond *pond, rules func(int, bool) bool) {
+import (
+	"errors"
+	"runtime"
+	"sync"
+)
+
+// Grid is the view of the board which processors read from and write to
+type Grid interface {
+	// Bounds returns the top-left corner and the size of the area in use
+	Bounds() (Location, Dimensions)
+	// Topology returns the shape of the surface of the grid
+	Topology() Topology
+	// GetNeighbors returns the neighbors of the given location based on the neighbor selector and topology
+	GetNeighbors(Location) ([]Location, error)
+	// Living returns the locations of all of the living organisms
+	Living() []Location
+	// IsAlive tests if the organism at the given location is alive
+	IsAlive(Location) bool
+	// SetAlive sets the state of the organism at the given location
+	SetAlive(Location, bool)
+}
+
+// StateGrid is implemented by grids whose organisms can pass through dying states
+// between being alive and being dead, as they do under the rules of the Generations family
+type StateGrid interface {
+	Grid
+	// States returns the number of states an organism can be in, including alive and dead
+	States() int
+	// State returns the state of the organism at the given location, which is StateDead, StateAlive or a dying state
+	State(Location) int
+	// SetState sets the state of the organism at the given location
+	SetState(Location, int)
+	// Dying returns the locations of all of the organisms which are dying
+	Dying() []Location
+}
+
+// NeighborhoodGrid is implemented by grids which know the direction of each neighbor of a location,
+// so that rules can be given the configuration of the living neighbors rather than just how many there are
+type NeighborhoodGrid interface {
+	Grid
+	// GetNeighborhood returns the same neighbors as GetNeighbors along with the direction each of them is in
+	GetNeighborhood(Location) ([]Location, []Neighborhood, error)
+}
+
+// organismRules decides if the organism at the given location is alive in the next generation,
+// where alive tells which organisms are alive in the current one
+type organismRules func(grid Grid, organism Location, alive func(Location) bool) (bool, error)
+
+// countingRules applies rules which are given the number of living neighbors of each organism
+func countingRules(rules func(int, bool) bool) organismRules {
+	return func(grid Grid, organism Location, alive func(Location) bool) (bool, error) {
+		neighbors, err := grid.GetNeighbors(organism)
+		if err != nil {
+			return false, err
+		}
+
+		numLivingNeighbors := 0
+		for _, neighbor := range neighbors {
+			if alive(neighbor) {
+				numLivingNeighbors++
+			}
+		}
+
+		return rules(numLivingNeighbors, alive(organism)), nil
+	}
+}
+
+// neighborhoodRules applies rules which are given the configuration of the living neighbors of each organism.
+// The grid must be a NeighborhoodGrid.
+func neighborhoodRules(rules func(Neighborhood, bool) bool) organismRules {
+	return func(grid Grid, organism Location, alive func(Location) bool) (bool, error) {
+		neighbors, directions, err := grid.(NeighborhoodGrid).GetNeighborhood(organism)
+		if err != nil {
+			return false, err
+		}
+
+		var neighborhood Neighborhood
+		for i, neighbor := range neighbors {
+			if alive(neighbor) {
+				neighborhood |= directions[i]
+			}
+		}
+
+		return rules(neighborhood, alive(organism)), nil
+	}
+}
+
+// checkNeighborhoodGrid returns an error if the grid does not know the direction of the neighbors of its organisms
+func checkNeighborhoodGrid(grid Grid) error {
+	if _, ok := grid.(NeighborhoodGrid); !ok {
+		return errors.New("grid does not know the direction of the neighbors of its organisms")
+	}
+	return nil
+}
+
+// multiState returns the grid as a StateGrid if its organisms have more states than alive and dead
+func multiState(grid Grid) (StateGrid, bool) {
+	states, ok := grid.(StateGrid)
+	if !ok || states.States() <= 2 {
+		return nil, false
+	}
+	return states, true
+}
+
+// processGenerations advances a grid whose organisms have more than two states. The rules decide which organisms
+// are born and which survive, as usual. An organism which does not survive starts dying, and passes through
+// each dying state, one generation at a time, without being counted as a neighbor, until it is dead.
+func processGenerations(grid StateGrid, rules organismRules) error {
+	living := grid.Living()
+	states := grid.States()
+
+	type ModifiedOrganism struct {
+		loc   Location
+		state int
+	}
+	modifications := make([]ModifiedOrganism, 0)
+
+	// Dying organisms take a step closer to being dead regardless of their neighbors
+	for _, organism := range grid.Dying() {
+		next := grid.State(organism) + 1
+		if next >= states {
+			next = StateDead
+		}
+		modifications = append(modifications, ModifiedOrganism{loc: organism, state: next})
+	}
+
+	processed := make(map[Location]bool)
+	for _, organism := range living {
+		neighbors, err := grid.GetNeighbors(organism)
+		if err != nil {
+			return err
+		}
+
+		for _, candidate := range append(neighbors, organism) {
+			if processed[candidate] {
+				continue
+			}
+			processed[candidate] = true
+
+			// Dying organisms were already taken care of
+			state := grid.State(candidate)
+			if state != StateAlive && state != StateDead {
+				continue
+			}
+
+			organismStatus, err := rules(grid, candidate, grid.IsAlive)
+			if err != nil {
+				continue
+			}
+
+			switch {
+			case state == StateAlive && !organismStatus:
+				modifications = append(modifications, ModifiedOrganism{loc: candidate, state: StateAlive + 1})
+			case state == StateDead && organismStatus:
+				modifications = append(modifications, ModifiedOrganism{loc: candidate, state: StateAlive})
+			}
+		}
+	}
+
+	for _, mod := range modifications {
+		grid.SetState(mod.loc, mod.state)
+	}
+
+	return nil
+}
+
+// Processor advances a Grid by a single generation using the given rules
+type Processor interface {
+	Process(grid Grid, rules func(int, bool) bool) error
+}
+
+// NeighborhoodProcessor is implemented by processors which can also advance a Grid with rules which are given
+// the configuration of the living neighbors of each organism, such as the rules returned by NeighborhoodTester.
+// The grid must be a NeighborhoodGrid.
+type NeighborhoodProcessor interface {
+	Processor
+	ProcessNeighborhoods(grid Grid, rules func(Neighborhood, bool) bool) error
+}
+
+// ProcessorFunc allows an ordinary function to be used as a Processor
+type ProcessorFunc func(grid Grid, rules func(int, bool) bool) error
+
+// Process calls the function with the given grid and rules
+func (f ProcessorFunc) Process(grid Grid, rules func(int, bool) bool) error {
+	return f(grid, rules)
+}
+
+// storeProvider is implemented by processors which need the living organisms stored in a particular way
+type storeProvider interface {
+	newStore(dims Dimensions) cellStore
+}
+
+// generationJumper is implemented by processors which can compute a far-future generation
+// without computing every generation in between
+type generationJumper interface {
+	jump(grid Grid, rules func(int, bool) bool, generations int) (bool, error)
+	jumpNeighborhoods(grid Grid, rules func(Neighborhood, bool) bool, generations int) (bool, error)
+}
+
+// simultaneousProcessor can be called like a ProcessorFunc
+type simultaneousProcessor func(grid Grid, rules func(int, bool) bool) error
+
+// SimultaneousProcessor simultaneously applies the given rules to the given grid. This is the default Conway processor.
+// Like every processor, it advances a StateGrid with more than two states under the rules of the Generations family.
+var SimultaneousProcessor = simultaneousProcessor(func(grid Grid, rules func(int, bool) bool) error {
+	return processSimultaneously(grid, countingRules(rules))
+})
+
+// Process computes the next generation of the grid
+func (f simultaneousProcessor) Process(grid Grid, rules func(int, bool) bool) error {
+	return f(grid, rules)
+}
+
+// ProcessNeighborhoods computes the next generation of the grid with rules which are given the configuration of the neighbors
+func (f simultaneousProcessor) ProcessNeighborhoods(grid Grid, rules func(Neighborhood, bool) bool) error {
+	if err := checkNeighborhoodGrid(grid); err != nil {
+		return err
+	}
+	return processSimultaneously(grid, neighborhoodRules(rules))
+}
+
+func processSimultaneously(grid Grid, rules organismRules) error {
+	if states, ok := multiState(grid); ok {
+		return processGenerations(states, rules)
+	}
+
 	// Blocks the completion of this function
 	done := make(chan bool, 1)
 
+	// Every organism that is processed is either living or the neighbor of one
+	living := grid.Living()
+	capacity := len(living) * (len(orthogonalOffsets) + len(obliqueOffsets) + 1)
+
 	////// Modifications handler /////
 	type ModifiedOrganism struct {
 		loc   Location
 		alive bool
 	}
 
-	modifications := make(chan ModifiedOrganism, pond.Dims.Capacity())
+	modifications := make(chan ModifiedOrganism, capacity)
 	blockModifications := make(chan bool, 1)
 
 	// This routine will make the actual modifications to the pond
@@ -20,7 +248,7 @@ func SimultaneousProcessor(pond *pond, rules func(int, bool) bool) {
 
 		for {
 			if mod, more := <-modifications; more {
-				pond.setOrganismState(mod.loc, mod.alive)
+				grid.SetAlive(mod.loc, mod.alive)
 			} else {
 				break
 			}
@@ -32,7 +260,7 @@ func SimultaneousProcessor(pond *pond, rules func(int, bool) bool) {
 
 	///// Start processing the living cells and their neighbors /////
 	// Process the queue
-	processingQueue := make(chan Location, pond.Dims.Capacity())
+	processingQueue := make(chan Location, capacity)
 	go func() {
 		processed := make(map[int]map[int]int)
 		for {
@@ -52,20 +280,9 @@ func SimultaneousProcessor(pond *pond, rules func(int, bool) bool) {
 					// Add organism to list of processed
 					processed[organism.Y][organism.X] = 1
 
-					// Retrieve all the infos
-					if neighbors, err := pond.GetNeighbors(organism); err == nil {
-						numLivingNeighbors := 0
-						for _, neighbor := range neighbors {
-							if pond.isOrganismAlive(neighbor) {
-								numLivingNeighbors++
-							}
-						}
-						currentlyAlive := pond.isOrganismAlive(organism)
-
-						// Check with the ruleset what this organism's current status is
-						organismStatus := rules(numLivingNeighbors, currentlyAlive)
-
-						if currentlyAlive != organismStatus { // If its status has changed, then we do stuff
+					// Check with the ruleset what this organism's current status is
+					if organismStatus, err := rules(grid, organism, grid.IsAlive); err == nil {
+						if grid.IsAlive(organism) != organismStatus { // If its status has changed, then we do stuff
 							modifications <- ModifiedOrganism{loc: organism, alive: organismStatus}
 						}
 					}
@@ -79,20 +296,288 @@ func SimultaneousProcessor(pond *pond, rules func(int, bool) bool) {
 	}()
 
 	// Add living organisms to processing queue
-	for _, organism := range pond.living.GetAll() {
+	var err error
+	for _, organism := range living {
 		processingQueue <- organism
 
 		// Now process the neighbors!
-		if neighbors, err := pond.GetNeighbors(organism); err == nil {
-			for _, neighbor := range neighbors {
-				processingQueue <- neighbor
-			}
+		var neighbors []Location
+		if neighbors, err = grid.GetNeighbors(organism); err != nil {
+			break
+		}
+		for _, neighbor := range neighbors {
+			processingQueue <- neighbor
 		}
 	}
 	close(processingQueue)
 
 	// Block until all modifications are done
 	<-done
+
+	return err
+}
+
+// parallelProcessor can be called like a ProcessorFunc
+type parallelProcessor func(grid Grid, rules func(int, bool) bool) error
+
+// ParallelProcessor splits the living organisms into bands of rows and computes the next state of each band
+// on a pool of workers, one for each of GOMAXPROCS. The grid is only modified once every band has been computed.
+// The GetNeighbors and GetNeighborhood methods of the grid must be safe to call from multiple goroutines.
+var ParallelProcessor = parallelProcessor(func(grid Grid, rules func(int, bool) bool) error {
+	return processParallel(grid, countingRules(rules))
+})
+
+// Process computes the next generation of the grid
+func (f parallelProcessor) Process(grid Grid, rules func(int, bool) bool) error {
+	return f(grid, rules)
+}
+
+// ProcessNeighborhoods computes the next generation of the grid with rules which are given the configuration of the neighbors
+func (f parallelProcessor) ProcessNeighborhoods(grid Grid, rules func(Neighborhood, bool) bool) error {
+	if err := checkNeighborhoodGrid(grid); err != nil {
+		return err
+	}
+	return processParallel(grid, neighborhoodRules(rules))
+}
+
+func processParallel(grid Grid, rules organismRules) error {
+	if states, ok := multiState(grid); ok {
+		return processGenerations(states, rules)
+	}
+
+	living := grid.Living()
+	if len(living) == 0 {
+		return nil
+	}
+
+	// Every worker reads from the same snapshot of the living organisms
+	alive := make(map[Location]bool, len(living))
+	isAlive := func(loc Location) bool {
+		return alive[loc]
+	}
+	minY, maxY := living[0].Y, living[0].Y
+	for _, loc := range living {
+		alive[loc] = true
+		if loc.Y < minY {
+			minY = loc.Y
+		}
+		if loc.Y > maxY {
+			maxY = loc.Y
+		}
+	}
+
+	// Split the living organisms into bands of rows, a few for each worker to even out the load
+	workers := runtime.GOMAXPROCS(0)
+	numBands := workers * 4
+	bandHeight := (maxY - minY + numBands) / numBands
+	bands := make([][]Location, numBands)
+	for _, loc := range living {
+		band := (loc.Y - minY) / bandHeight
+		bands[band] = append(bands[band], loc)
+	}
+
+	type ModifiedOrganism struct {
+		loc   Location
+		alive bool
+	}
+
+	// Each band processes its living organisms and their neighbors, which can be in other bands
+	// when they are on the border of the band or past an edge of the grid.
+	// Those organisms can be processed by more than one band, which will come to the same conclusion.
+	process := func(band []Location) ([]ModifiedOrganism, error) {
+		modifications := make([]ModifiedOrganism, 0)
+		processed := make(map[Location]bool)
+
+		for _, organism := range band {
+			neighbors, err := grid.GetNeighbors(organism)
+			if err != nil {
+				return nil, err
+			}
+
+			for _, candidate := range append(neighbors, organism) {
+				if processed[candidate] {
+					continue
+				}
+				processed[candidate] = true
+
+				organismStatus, err := rules(grid, candidate, isAlive)
+				if err != nil {
+					continue
+				}
+				if organismStatus != alive[candidate] {
+					modifications = append(modifications, ModifiedOrganism{loc: candidate, alive: organismStatus})
+				}
+			}
+		}
+
+		return modifications, nil
+	}
+
+	queue := make(chan int, numBands)
+	for band := range bands {
+		queue <- band
+	}
+	close(queue)
+
+	results := make([][]ModifiedOrganism, numBands)
+	errs := make([]error, numBands)
+
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for band := range queue {
+				results[band], errs[band] = process(bands[band])
+			}
+		}()
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+
+	// Now that every band is done, commit all of the modifications
+	for _, modifications := range results {
+		for _, mod := range modifications {
+			grid.SetAlive(mod.loc, mod.alive)
+		}
+	}
+
+	return nil
+}
+
+type bitboardProcessor struct{}
+
+// BitboardProcessor applies the rules to 64 organisms at a time by storing them as bits packed into words
+// and counting their neighbors with bitwise operations. It produces the same results as the SimultaneousProcessor.
+// A Life created with this processor stores its organisms in a bitboard. Any other Grid, one whose organisms
+// have more than two states, or rules which are given the configuration of the neighbors, are handed to the SimultaneousProcessor.
+var BitboardProcessor Processor = bitboardProcessor{}
+
+func (t bitboardProcessor) newStore(dims Dimensions) cellStore {
+	return newBitboard(Location{}, dims)
+}
+
+// Process computes the next generation of the grid
+func (t bitboardProcessor) Process(grid Grid, rules func(int, bool) bool) error {
+	if pond, ok := grid.(*pond); ok && pond.states <= 2 {
+		if board, ok := pond.living.(*bitboard); ok {
+			board.step(pond, newBitboardRules(rules))
+			return nil
+		}
+	}
+
+	return SimultaneousProcessor.Process(grid, rules)
+}
+
+// ProcessNeighborhoods hands the grid to the SimultaneousProcessor, since the bitboard only counts the neighbors
+func (t bitboardProcessor) ProcessNeighborhoods(grid Grid, rules func(Neighborhood, bool) bool) error {
+	return SimultaneousProcessor.ProcessNeighborhoods(grid, rules)
+}
+
+type hashLifeProcessor struct {
+	mutex  sync.Mutex
+	engine *hashlife
+}
+
+// NewHashLifeProcessor creates a processor which uses the HashLife algorithm, remembering the future of
+// every square of organisms it has seen so that it can jump ahead by any number of generations at once.
+// It is only applicable to grids with an unbounded topology, whose organisms are only alive or dead, and rules which
+// do not give birth to organisms without any neighbors, whether they are given the number or the configuration of
+// the neighbors. Any other grid is handed to the SimultaneousProcessor.
+func NewHashLifeProcessor() Processor {
+	return new(hashLifeProcessor)
+}
+
+// engineFor returns the engine which can process the given grid and rules, or nil if HashLife is not applicable.
+// The rules are either counting rules or, when they are given the configuration of the neighbors, a neighborhood table.
+func (t *hashLifeProcessor) engineFor(grid Grid, rules *bitboardRules, neighborhoods *neighborhoodTable) *hashlife {
+	pond, ok := grid.(*pond)
+	if !ok || pond.topology != TopologyUnbounded || pond.states > 2 {
+		return nil
+	}
+
+	// Everything that was remembered is only valid for the same rules and neighbors
+	if t.engine == nil || t.engine.selector != pond.neighborsSelector || !t.engine.sameRules(rules, neighborhoods) {
+		if neighborhoods != nil {
+			t.engine = newNeighborhoodHashlife(neighborhoods, pond.neighborsSelector)
+		} else {
+			t.engine = newHashlife(rules, pond.neighborsSelector)
+		}
+	}
+
+	return t.engine
+}
+
+// Process computes the next generation of the grid
+func (t *hashLifeProcessor) Process(grid Grid, rules func(int, bool) bool) error {
+	if jumped, err := t.jump(grid, rules, 1); jumped || err != nil {
+		return err
+	}
+
+	return SimultaneousProcessor.Process(grid, rules)
+}
+
+// ProcessNeighborhoods computes the next generation of the grid with rules which are given the configuration of the neighbors
+func (t *hashLifeProcessor) ProcessNeighborhoods(grid Grid, rules func(Neighborhood, bool) bool) error {
+	if jumped, err := t.jumpNeighborhoods(grid, rules, 1); jumped || err != nil {
+		return err
+	}
+
+	return SimultaneousProcessor.ProcessNeighborhoods(grid, rules)
+}
+
+func (t *hashLifeProcessor) jump(grid Grid, rules func(int, bool) bool, generations int) (bool, error) {
+	if rules(0, false) {
+		return false, nil
+	}
+
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	return t.advance(grid, t.engineFor(grid, newBitboardRules(rules), nil), generations)
+}
+
+func (t *hashLifeProcessor) jumpNeighborhoods(grid Grid, rules func(Neighborhood, bool) bool, generations int) (bool, error) {
+	if rules(0, false) {
+		return false, nil
+	}
+
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	return t.advance(grid, t.engineFor(grid, nil, newNeighborhoodTable(rules)), generations)
+}
+
+// advance moves the grid forward by the given number of generations with the engine, unless it is nil
+func (t *hashLifeProcessor) advance(grid Grid, engine *hashlife, generations int) (bool, error) {
+	if engine == nil {
+		return false, nil
+	}
+
+	next := engine.advance(grid.Living(), generations)
+
+	// Only touch the organisms whose state changed
+	alive := make(map[Location]bool, len(next))
+	for _, loc := range next {
+		alive[loc] = true
+	}
+	for _, loc := range grid.Living() {
+		if !alive[loc] {
+			grid.SetAlive(loc, false)
+		}
+	}
+	for _, loc := range next {
+		if !grid.IsAlive(loc) {
+			grid.SetAlive(loc, true)
+		}
+	}
+
+	return true, nil
 }
 
 // vim: set foldmethod=marker:
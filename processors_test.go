@@ -8,14 +8,14 @@ import "testing"
 // number of generations as there are boards in the 'expected' slice.
 // Compares each generation with the correct slice
 func testProcessor(t *testing.T,
-	processor func(pond *pond, rules func(int, bool) bool),
+	processor Processor,
 	rules func(int, bool) bool,
 	size Dimensions,
 	init func(Dimensions, Location) []Location,
 	expected []*pond) {
 
 	// Build the initial pond
-	pond, err := newPond(size, newTracker(), NeighborsAll)
+	pond, err := newProcessorPond(processor, size, NeighborsAll, TopologyPlane)
 	if err != nil {
 		t.Fatalf("Unable to create pond: %s\n", err)
 	}
@@ -23,7 +23,9 @@ func testProcessor(t *testing.T,
 
 	// Go through one generation
 	for i := 0; i < len(expected); i++ {
-		processor(pond, rules)
+		if err := processor.Process(pond, rules); err != nil {
+			t.Fatalf("Unable to process pond: %s\n", err)
+		}
 
 		// Compare the pond with the expected version
 		if !pond.Equals(expected[i]) {
@@ -32,11 +34,61 @@ func testProcessor(t *testing.T,
 	}
 }
 
+// Creates a pond which stores its organisms the way the processor wants
+func newProcessorPond(processor Processor, size Dimensions, neighbors neighborsSelector, topology Topology) (*pond, error) {
+	var living cellStore = newTracker()
+	if provider, ok := processor.(storeProvider); ok {
+		living = provider.newStore(size)
+	}
+	return newPond(size, living, neighbors, topology)
+}
+
+// Compares the given processor against the SimultaneousProcessor on random soups
+// for each neighbor selector and topology
+func testProcessorMatchesSimultaneous(t *testing.T, processor Processor, size Dimensions, generations int) {
+	selectors := []neighborsSelector{NeighborsAll, NeighborsOrthogonal, NeighborsOblique}
+	rules := []func(int, bool) bool{ConwayTester(), RulesTester(&Rules{Survive: []int{2, 3}, Born: []int{3, 6}})}
+
+	for topology := TopologyPlane; topology <= TopologyCylinder; topology++ {
+		for _, selector := range selectors {
+			for _, rule := range rules {
+				seed := Random(size, Location{}, 40)
+
+				expected, err := newPond(size, newTracker(), selector, topology)
+				if err != nil {
+					t.Fatalf("Unable to create pond: %s\n", err)
+				}
+				expected.SetOrganisms(seed)
+
+				actual, err := newProcessorPond(processor, size, selector, topology)
+				if err != nil {
+					t.Fatalf("Unable to create pond: %s\n", err)
+				}
+				actual.SetOrganisms(seed)
+
+				for i := 0; i < generations; i++ {
+					if err := SimultaneousProcessor.Process(expected, rule); err != nil {
+						t.Fatalf("Unable to process pond: %s\n", err)
+					}
+					if err := processor.Process(actual, rule); err != nil {
+						t.Fatalf("Unable to process pond: %s\n", err)
+					}
+
+					if !actual.living.Equals(expected.living) {
+						t.Fatalf("%s topology with %s neighbors at generation %d, actual board\n%s\ndoes not match expected\n%s\n",
+							topology.String(), selector.String(), i+1, actual.String(), expected.String())
+					}
+				}
+			}
+		}
+	}
+}
+
 func createPondsFromTrackers(t *testing.T, dims Dimensions, trackers []*tracker) []*pond {
 	var err error
 	ponds := make([]*pond, len(trackers))
 	for i, tracker := range trackers {
-		ponds[i], err = newPond(dims, tracker, NeighborsAll)
+		ponds[i], err = newPond(dims, tracker, NeighborsAll, TopologyPlane)
 		if err != nil {
 			t.Fatal("Unable to create pond")
 		}
@@ -365,6 +417,72 @@ func generateBoat(t *testing.T) (Dimensions, func(Dimensions, Location) []Locati
 	return size, Boat, createPondsFromTrackers(t, size, expected)
 }
 
+//////////////////////// Custom processor ////////////////////////
+
+// A processor which only relies on what the Grid interface provides
+func naiveProcessor(grid Grid, rules func(int, bool) bool) error {
+	origin, dims := grid.Bounds()
+
+	modifications := make(map[Location]bool)
+	for y := origin.Y; y < origin.Y+dims.Height; y++ {
+		for x := origin.X; x < origin.X+dims.Width; x++ {
+			loc := Location{X: x, Y: y}
+			neighbors, err := grid.GetNeighbors(loc)
+			if err != nil {
+				return err
+			}
+
+			count := 0
+			for _, neighbor := range neighbors {
+				if grid.IsAlive(neighbor) {
+					count++
+				}
+			}
+
+			alive := grid.IsAlive(loc)
+			if rules(count, alive) != alive {
+				modifications[loc] = !alive
+			}
+		}
+	}
+
+	for loc, alive := range modifications {
+		grid.SetAlive(loc, alive)
+	}
+
+	return nil
+}
+
+func TestProcessorFunc(t *testing.T) {
+	size := Dimensions{Height: 16, Width: 16}
+	initialLocations := Random(size, Location{}, 50)
+
+	expected, err := newPond(size, newTracker(), NeighborsAll, TopologyTorus)
+	if err != nil {
+		t.Fatalf("Unable to create pond: %s\n", err)
+	}
+	expected.SetOrganisms(initialLocations)
+
+	actual, err := expected.Clone()
+	if err != nil {
+		t.Fatalf("Unable to clone pond: %s\n", err)
+	}
+
+	var processor Processor = ProcessorFunc(naiveProcessor)
+	for i := 0; i < 10; i++ {
+		if err := SimultaneousProcessor.Process(expected, ConwayTester()); err != nil {
+			t.Fatalf("Unable to process pond: %s\n", err)
+		}
+		if err := processor.Process(actual, ConwayTester()); err != nil {
+			t.Fatalf("Unable to process pond: %s\n", err)
+		}
+
+		if !actual.Equals(expected) {
+			t.Fatalf("At generation %d, actual board\n%s\ndoes not match expected\n%s\n", i+1, actual.String(), expected.String())
+		}
+	}
+}
+
 //////////////////////// Simultaneous processor ////////////////////////
 
 func testProcessorSimultaneousRulesConway(t *testing.T,
@@ -385,13 +503,13 @@ func TestProcessorSimultaneousRulesConwayRandom(t *testing.T) {
 	size := Dimensions{Height: 16, Width: 16}
 	initialLocations := Random(size, Location{}, 80)
 
-	pondInitialSnapshot, err := newPond(size, newTracker(), NeighborsAll)
+	pondInitialSnapshot, err := newPond(size, newTracker(), NeighborsAll, TopologyPlane)
 	if err != nil {
 		t.Fatalf("Unable to create pond: %s\n", err)
 	}
 	pondInitialSnapshot.SetOrganisms(initialLocations)
 
-	pondWorker, err := newPond(size, newTracker(), NeighborsAll)
+	pondWorker, err := newPond(size, newTracker(), NeighborsAll, TopologyPlane)
 	if err != nil {
 		t.Fatalf("Unable to create pond: %s\n", err)
 	}
@@ -451,10 +569,468 @@ func TestProcessorSimultaneousRulesConwayGliders(t *testing.T) {
 	testProcessorSimultaneousRulesConway(t, size, init, expected)
 }
 
+func TestProcessorSimultaneousRulesConwayTorus(t *testing.T) {
+	// A glider travels one cell diagonally every four generations,
+	// so on a torus it should return to where it started
+	size := Dimensions{Height: 5, Width: 5}
+
+	pond, err := newPond(size, newTracker(), NeighborsAll, TopologyTorus)
+	if err != nil {
+		t.Fatalf("Unable to create pond: %s\n", err)
+	}
+	pond.SetOrganisms(Gliders(size, Location{}))
+
+	expected, err := pond.Clone()
+	if err != nil {
+		t.Fatalf("Unable to clone pond: %s\n", err)
+	}
+
+	for i := 0; i < size.Width*4; i++ {
+		SimultaneousProcessor(pond, ConwayTester())
+
+		if pond.living.Count() != 5 {
+			t.Fatalf("At generation %d the glider has %d cells instead of 5\n%s\n", i+1, pond.living.Count(), pond.String())
+		}
+	}
+
+	if !pond.Equals(expected) {
+		t.Fatalf("Actual board\n%s\ndoes not match expected\n%s\n", pond.String(), expected.String())
+	}
+}
+
+func TestProcessorSimultaneousRulesConwayUnbounded(t *testing.T) {
+	// A glider travels one cell diagonally every four generations,
+	// so on an unbounded pond it should travel well past its original area
+	size := Dimensions{Height: 3, Width: 3}
+
+	pond, err := newPond(size, newTracker(), NeighborsAll, TopologyUnbounded)
+	if err != nil {
+		t.Fatalf("Unable to create pond: %s\n", err)
+	}
+	pond.SetOrganisms(Gliders(size, Location{}))
+
+	const distance = 10
+	for i := 0; i < distance*4; i++ {
+		SimultaneousProcessor(pond, ConwayTester())
+	}
+
+	expected, err := newPond(size, newTracker(), NeighborsAll, TopologyUnbounded)
+	if err != nil {
+		t.Fatalf("Unable to create pond: %s\n", err)
+	}
+	expected.SetOrganisms(Gliders(size, Location{X: distance, Y: distance}))
+
+	if !pond.Equals(expected) {
+		t.Fatalf("Actual board\n%s\ndoes not match expected\n%s\n", pond.String(), expected.String())
+	}
+}
+
+//////////////////////// Bitboard processor ////////////////////////
+
+func testProcessorBitboardRulesConway(t *testing.T,
+	size Dimensions,
+	init func(Dimensions, Location) []Location,
+	expected []*pond) {
+
+	testProcessor(t,
+		BitboardProcessor,
+		ConwayTester(),
+		size,
+		init,
+		expected)
+}
+
+func TestProcessorBitboardRulesConwayBlinker(t *testing.T) {
+	size, init, expected := generateBlinkers(t)
+	testProcessorBitboardRulesConway(t, size, init, expected)
+}
+
+func TestProcessorBitboardRulesConwayToad(t *testing.T) {
+	size, init, expected := generateToads(t)
+	testProcessorBitboardRulesConway(t, size, init, expected)
+}
+
+func TestProcessorBitboardRulesConwayBeacon(t *testing.T) {
+	size, init, expected := generateBeacons(t)
+	testProcessorBitboardRulesConway(t, size, init, expected)
+}
+
+func TestProcessorBitboardRulesConwayBlock(t *testing.T) {
+	size, init, expected := generateBlock(t)
+	testProcessorBitboardRulesConway(t, size, init, expected)
+}
+
+func TestProcessorBitboardRulesConwayPulsar(t *testing.T) {
+	size, init, expected := generatePulsar(t)
+	testProcessorBitboardRulesConway(t, size, init, expected)
+}
+
+func TestProcessorBitboardRulesConwayGliders(t *testing.T) {
+	size, init, expected := generateGlider(t)
+	testProcessorBitboardRulesConway(t, size, init, expected)
+}
+
+func TestProcessorBitboardMatchesSimultaneous(t *testing.T) {
+	// Widths which do not fill up the words
+	testProcessorMatchesSimultaneous(t, BitboardProcessor, Dimensions{Width: 70, Height: 9}, 8)
+	testProcessorMatchesSimultaneous(t, BitboardProcessor, Dimensions{Width: 5, Height: 6}, 8)
+}
+
+func TestProcessorBitboardFallback(t *testing.T) {
+	// A pond which is not stored in a bitboard is still processed
+	size, init, expected := generateGlider(t)
+	pond, err := newPond(size, newTracker(), NeighborsAll, TopologyPlane)
+	if err != nil {
+		t.Fatalf("Unable to create pond: %s\n", err)
+	}
+	pond.SetOrganisms(init(size, Location{}))
+
+	if err := BitboardProcessor.Process(pond, ConwayTester()); err != nil {
+		t.Fatalf("Unable to process pond: %s\n", err)
+	}
+
+	if !pond.Equals(expected[0]) {
+		t.Fatalf("Actual board\n%s\ndoes not match expected\n%s\n", pond.String(), expected[0].String())
+	}
+}
+
+func TestProcessorHashLifeMatchesSimultaneous(t *testing.T) {
+	// Bounded topologies are handed to the simultaneous processor
+	testProcessorMatchesSimultaneous(t, NewHashLifeProcessor(), Dimensions{Width: 20, Height: 15}, 8)
+}
+
+func TestProcessorHashLifeFallback(t *testing.T) {
+	// Rules which give birth without neighbors cannot be computed by HashLife
+	rules := RulesTester(&Rules{Survive: []int{2, 3}, Born: []int{0, 3}})
+	size := Dimensions{Width: 12, Height: 12}
+	seed := Random(size, Location{}, 40)
+
+	expected, err := newPond(size, newTracker(), NeighborsAll, TopologyUnbounded)
+	if err != nil {
+		t.Fatalf("Unable to create pond: %s\n", err)
+	}
+	expected.SetOrganisms(seed)
+
+	actual, err := expected.Clone()
+	if err != nil {
+		t.Fatalf("Unable to clone pond: %s\n", err)
+	}
+
+	processor := NewHashLifeProcessor()
+	for i := 0; i < 3; i++ {
+		if err := SimultaneousProcessor.Process(expected, rules); err != nil {
+			t.Fatalf("Unable to process pond: %s\n", err)
+		}
+		if err := processor.Process(actual, rules); err != nil {
+			t.Fatalf("Unable to process pond: %s\n", err)
+		}
+	}
+
+	if !actual.Equals(expected) {
+		t.Fatalf("Actual board\n%s\ndoes not match expected\n%s\n", actual.String(), expected.String())
+	}
+}
+
+func testProcessorParallelRulesConway(t *testing.T,
+	size Dimensions,
+	init func(Dimensions, Location) []Location,
+	expected []*pond) {
+
+	testProcessor(t,
+		ParallelProcessor,
+		ConwayTester(),
+		size,
+		init,
+		expected)
+}
+
+func TestProcessorParallelRulesConwayBlinker(t *testing.T) {
+	size, init, expected := generateBlinkers(t)
+	testProcessorParallelRulesConway(t, size, init, expected)
+}
+
+func TestProcessorParallelRulesConwayPulsar(t *testing.T) {
+	size, init, expected := generatePulsar(t)
+	testProcessorParallelRulesConway(t, size, init, expected)
+}
+
+func TestProcessorParallelRulesConwayGliders(t *testing.T) {
+	size, init, expected := generateGlider(t)
+	testProcessorParallelRulesConway(t, size, init, expected)
+}
+
+func TestProcessorParallelMatchesSimultaneous(t *testing.T) {
+	// Organisms on the borders of the bands and past the edges of the grid
+	testProcessorMatchesSimultaneous(t, ParallelProcessor, Dimensions{Width: 40, Height: 37}, 8)
+	testProcessorMatchesSimultaneous(t, ParallelProcessor, Dimensions{Width: 3, Height: 5}, 8)
+}
+
+func TestProcessorParallelEmpty(t *testing.T) {
+	pond, err := newPond(Dimensions{Width: 4, Height: 4}, newTracker(), NeighborsAll, TopologyPlane)
+	if err != nil {
+		t.Fatalf("Unable to create pond: %s\n", err)
+	}
+
+	if err := ParallelProcessor.Process(pond, ConwayTester()); err != nil {
+		t.Fatalf("Unable to process pond: %s\n", err)
+	}
+
+	if pond.living.Count() != 0 {
+		t.Fatalf("Found %d living organisms when there should be none\n", pond.living.Count())
+	}
+}
+
+// pondStates returns the state of every organism of the pond which is not dead
+func pondStates(p *pond) map[Location]int {
+	states := make(map[Location]int)
+	for _, loc := range p.Living() {
+		states[loc] = StateAlive
+	}
+	for _, loc := range p.Dying() {
+		states[loc] = p.State(loc)
+	}
+	return states
+}
+
+func testStatesMatch(t *testing.T, expected, actual map[Location]int) {
+	if len(expected) != len(actual) {
+		t.Fatalf("Found %d organisms which are not dead instead of %d\n", len(actual), len(expected))
+	}
+	for loc, state := range expected {
+		if actual[loc] != state {
+			t.Fatalf("Organism %s is in state %d instead of %d\n", loc.String(), actual[loc], state)
+		}
+	}
+}
+
+func TestProcessorGenerationsBriansBrain(t *testing.T) {
+	rules, err := ParseRules("/2/3")
+	if err != nil {
+		t.Fatalf("Unable to parse rules: %s\n", err)
+	}
+
+	processors := []Processor{SimultaneousProcessor, ParallelProcessor, BitboardProcessor, NewHashLifeProcessor()}
+	for _, processor := range processors {
+		for _, topology := range []Topology{TopologyTorus, TopologyUnbounded} {
+			p, err := newProcessorPond(processor, Dimensions{Width: 10, Height: 10}, NeighborsAll, topology)
+			if err != nil {
+				t.Fatalf("Unable to create pond: %s\n", err)
+			}
+			p.states = rules.States
+			p.SetOrganisms([]Location{{X: 4, Y: 4}, {X: 5, Y: 4}})
+
+			// The pair dies without any neighbors to survive with and gives birth above and below itself
+			if err := processor.Process(p, RulesTester(rules)); err != nil {
+				t.Fatalf("Unable to process pond: %s\n", err)
+			}
+			testStatesMatch(t, map[Location]int{
+				{X: 4, Y: 3}: StateAlive, {X: 5, Y: 3}: StateAlive, {X: 4, Y: 5}: StateAlive, {X: 5, Y: 5}: StateAlive,
+				{X: 4, Y: 4}: 2, {X: 5, Y: 4}: 2,
+			}, pondStates(p))
+
+			// The dying organisms cannot be born again before they are dead
+			if err := processor.Process(p, RulesTester(rules)); err != nil {
+				t.Fatalf("Unable to process pond: %s\n", err)
+			}
+			testStatesMatch(t, map[Location]int{
+				{X: 4, Y: 2}: StateAlive, {X: 5, Y: 2}: StateAlive, {X: 4, Y: 6}: StateAlive, {X: 5, Y: 6}: StateAlive,
+				{X: 3, Y: 4}: StateAlive, {X: 6, Y: 4}: StateAlive,
+				{X: 4, Y: 3}: 2, {X: 5, Y: 3}: 2, {X: 4, Y: 5}: 2, {X: 5, Y: 5}: 2,
+			}, pondStates(p))
+		}
+	}
+}
+
+func TestProcessorGenerationsMatchSimultaneous(t *testing.T) {
+	rules, err := ParseRules("345/2/4")
+	if err != nil {
+		t.Fatalf("Unable to parse rules: %s\n", err)
+	}
+
+	size := Dimensions{Width: 30, Height: 30}
+	seed := SeededRandom(size, Location{}, 30, 42)
+
+	expected, err := newPond(size, newTracker(), NeighborsAll, TopologyTorus)
+	if err != nil {
+		t.Fatalf("Unable to create pond: %s\n", err)
+	}
+	expected.states = rules.States
+	expected.SetOrganisms(seed)
+
+	processors := []Processor{ParallelProcessor, BitboardProcessor, NewHashLifeProcessor()}
+	actual := make([]*pond, len(processors))
+	for i, processor := range processors {
+		if actual[i], err = newProcessorPond(processor, size, NeighborsAll, TopologyTorus); err != nil {
+			t.Fatalf("Unable to create pond: %s\n", err)
+		}
+		actual[i].states = rules.States
+		actual[i].SetOrganisms(seed)
+	}
+
+	for gen := 0; gen < 15; gen++ {
+		if err := SimultaneousProcessor.Process(expected, RulesTester(rules)); err != nil {
+			t.Fatalf("Unable to process pond: %s\n", err)
+		}
+		for i, processor := range processors {
+			if err := processor.Process(actual[i], RulesTester(rules)); err != nil {
+				t.Fatalf("Unable to process pond: %s\n", err)
+			}
+			testStatesMatch(t, pondStates(expected), pondStates(actual[i]))
+		}
+	}
+
+	if len(expected.Dying()) == 0 {
+		t.Fatal("None of the organisms were dying")
+	}
+}
+
+func TestProcessorNeighborhoodsDomino(t *testing.T) {
+	rules, err := ParseRules("B2-a/S12")
+	if err != nil {
+		t.Fatalf("Unable to parse rules: %s\n", err)
+	}
+	domino := []Location{{X: 4, Y: 4}, {X: 5, Y: 4}}
+
+	processors := []NeighborhoodProcessor{SimultaneousProcessor, ParallelProcessor, BitboardProcessor.(NeighborhoodProcessor), new(hashLifeProcessor)}
+	for _, processor := range processors {
+		for _, topology := range []Topology{TopologyPlane, TopologyUnbounded} {
+			p, err := newProcessorPond(processor, Dimensions{Width: 10, Height: 10}, NeighborsAll, topology)
+			if err != nil {
+				t.Fatalf("Unable to create pond: %s\n", err)
+			}
+			p.SetOrganisms(domino)
+
+			// The organisms above and below the domino only have 2a configurations, so the domino is a still life
+			for i := 0; i < 3; i++ {
+				if err := processor.ProcessNeighborhoods(p, NeighborhoodTester(rules)); err != nil {
+					t.Fatalf("Unable to process pond: %s\n", err)
+				}
+			}
+			testLocationsMatch(t, domino, p.Living())
+
+			// Counting the neighbors gives birth to them
+			if err := processor.Process(p, RulesTester(rules)); err != nil {
+				t.Fatalf("Unable to process pond: %s\n", err)
+			}
+			if len(p.Living()) != 6 {
+				t.Fatalf("Domino became %d organisms instead of 6\n", len(p.Living()))
+			}
+		}
+	}
+}
+
+func TestProcessorNeighborhoodsMatchSimultaneous(t *testing.T) {
+	tlife, err := ParseRules("B3/S2-i34q")
+	if err != nil {
+		t.Fatalf("Unable to parse rules: %s\n", err)
+	}
+	rules := NeighborhoodTester(tlife)
+	size := Dimensions{Width: 24, Height: 20}
+
+	processors := []NeighborhoodProcessor{ParallelProcessor, BitboardProcessor.(NeighborhoodProcessor), new(hashLifeProcessor)}
+	for _, topology := range []Topology{TopologyTorus, TopologyUnbounded, TopologyKleinBottle} {
+		for _, selector := range []neighborsSelector{NeighborsAll, NeighborsOrthogonal} {
+			seed := SeededRandom(size, Location{}, 40, int64(topology))
+
+			expected, err := newPond(size, newTracker(), selector, topology)
+			if err != nil {
+				t.Fatalf("Unable to create pond: %s\n", err)
+			}
+			expected.SetOrganisms(seed)
+
+			actual := make([]*pond, len(processors))
+			for i, processor := range processors {
+				if actual[i], err = newProcessorPond(processor, size, selector, topology); err != nil {
+					t.Fatalf("Unable to create pond: %s\n", err)
+				}
+				actual[i].SetOrganisms(seed)
+			}
+
+			for gen := 0; gen < 10; gen++ {
+				if err := SimultaneousProcessor.ProcessNeighborhoods(expected, rules); err != nil {
+					t.Fatalf("Unable to process pond: %s\n", err)
+				}
+				for i, processor := range processors {
+					if err := processor.ProcessNeighborhoods(actual[i], rules); err != nil {
+						t.Fatalf("Unable to process pond: %s\n", err)
+					}
+					if !actual[i].living.Equals(expected.living) {
+						t.Fatalf("%s topology with %s neighbors at generation %d, actual board\n%s\ndoes not match expected\n%s\n",
+							topology.String(), selector.String(), gen+1, actual[i].String(), expected.String())
+					}
+				}
+			}
+		}
+	}
+}
+
+func TestProcessorNeighborhoodsGenerations(t *testing.T) {
+	rules, err := ParseRules("B2-a/S/C3")
+	if err != nil {
+		t.Fatalf("Unable to parse rules: %s\n", err)
+	}
+
+	p, err := newPond(Dimensions{Width: 10, Height: 10}, newTracker(), NeighborsAll, TopologyTorus)
+	if err != nil {
+		t.Fatalf("Unable to create pond: %s\n", err)
+	}
+	p.states = rules.States
+	p.SetOrganisms([]Location{{X: 4, Y: 4}, {X: 5, Y: 4}})
+
+	// Unlike Brian's Brain, nothing is born above or below the pair, since those are 2a configurations
+	if err := ParallelProcessor.ProcessNeighborhoods(p, NeighborhoodTester(rules)); err != nil {
+		t.Fatalf("Unable to process pond: %s\n", err)
+	}
+	testStatesMatch(t, map[Location]int{{X: 4, Y: 4}: 2, {X: 5, Y: 4}: 2}, pondStates(p))
+}
+
+func TestProcessorNeighborhoodsGrid(t *testing.T) {
+	// A grid which does not know the direction of its neighbors cannot be given the configuration of the neighbors
+	p, err := newPond(Dimensions{Width: 4, Height: 4}, newTracker(), NeighborsAll, TopologyPlane)
+	if err != nil {
+		t.Fatalf("Unable to create pond: %s\n", err)
+	}
+	grid := struct{ Grid }{p}
+
+	if err := SimultaneousProcessor.ProcessNeighborhoods(grid, NeighborhoodTester(GetConwayRules())); err == nil {
+		t.Fatal("Processed a grid which does not know the direction of the neighbors")
+	}
+}
+
+func BenchmarkProcessorBitboardRulesConwayRandom(b *testing.B) {
+	size := Dimensions{Height: 1000, Width: 1000}
+	pond, err := newProcessorPond(BitboardProcessor, size, NeighborsAll, TopologyTorus)
+	if err != nil {
+		b.Fatalf("Unable to create pond: %s\n", err)
+	}
+	pond.SetOrganisms(Random(size, Location{}, 35))
+	rules := ConwayTester()
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		BitboardProcessor.Process(pond, rules)
+	}
+}
+
+func BenchmarkProcessorParallelRulesConwayRandom(b *testing.B) {
+	size := Dimensions{Height: 200, Width: 200}
+	pond, err := newPond(size, newTracker(), NeighborsAll, TopologyTorus)
+	if err != nil {
+		b.Fatalf("Unable to create pond: %s\n", err)
+	}
+	pond.SetOrganisms(Random(size, Location{}, 35))
+	rules := ConwayTester()
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		ParallelProcessor.Process(pond, rules)
+	}
+}
+
 func BenchmarkProcessorSimultaneousRulesConwayPulsar(b *testing.B) {
 	// Build the initial pond
 	size := Dimensions{Height: 33, Width: 33}
-	pond, err := newPond(size, newTracker(), NeighborsAll)
+	pond, err := newPond(size, newTracker(), NeighborsAll, TopologyPlane)
 	if err != nil {
 		b.Fatalf("Unable to create pond: %s\n", err)
 	}
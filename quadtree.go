@@ -0,0 +1,155 @@
+package life
+
+// quadNode is a square of 2^level cells on each side which is split into four quadrants.
+// Nodes are canonicalized by their quadtree so that identical squares share one node.
+type quadNode struct {
+	level          uint
+	nw, ne, sw, se *quadNode
+	population     int
+}
+
+// size returns the number of cells on each side of the node
+func (t *quadNode) size() int {
+	return 1 << t.level
+}
+
+type quadKey struct {
+	nw, ne, sw, se *quadNode
+}
+
+// quadtree creates and tracks canonical nodes
+type quadtree struct {
+	nodes map[quadKey]*quadNode
+	empty []*quadNode // The empty node of each level
+	alive *quadNode   // The single living cell
+}
+
+// node returns the canonical node with the given quadrants
+func (t *quadtree) node(nw, ne, sw, se *quadNode) *quadNode {
+	key := quadKey{nw: nw, ne: ne, sw: sw, se: se}
+	if n, keyExists := t.nodes[key]; keyExists {
+		return n
+	}
+
+	n := &quadNode{
+		level:      nw.level + 1,
+		nw:         nw,
+		ne:         ne,
+		sw:         sw,
+		se:         se,
+		population: nw.population + ne.population + sw.population + se.population,
+	}
+	t.nodes[key] = n
+
+	return n
+}
+
+// emptyNode returns the node of the given level with no living cells
+func (t *quadtree) emptyNode(level uint) *quadNode {
+	for uint(len(t.empty)) <= level {
+		smaller := t.empty[len(t.empty)-1]
+		t.empty = append(t.empty, t.node(smaller, smaller, smaller, smaller))
+	}
+	return t.empty[level]
+}
+
+// leaf returns the level 0 node of the given state
+func (t *quadtree) leaf(alive bool) *quadNode {
+	if alive {
+		return t.alive
+	}
+	return t.emptyNode(0)
+}
+
+// build creates the node of the given level whose top-left corner is at the given origin
+// from the living locations, all of which must be within the node
+func (t *quadtree) build(living []Location, level uint, origin Location) *quadNode {
+	if len(living) == 0 {
+		return t.emptyNode(level)
+	}
+	if level == 0 {
+		return t.alive
+	}
+
+	half := 1 << (level - 1)
+	quadrants := make([][]Location, 4)
+	for _, loc := range living {
+		quadrant := 0
+		if loc.X >= origin.X+half {
+			quadrant++
+		}
+		if loc.Y >= origin.Y+half {
+			quadrant += 2
+		}
+		quadrants[quadrant] = append(quadrants[quadrant], loc)
+	}
+
+	return t.node(
+		t.build(quadrants[0], level-1, origin),
+		t.build(quadrants[1], level-1, Location{X: origin.X + half, Y: origin.Y}),
+		t.build(quadrants[2], level-1, Location{X: origin.X, Y: origin.Y + half}),
+		t.build(quadrants[3], level-1, Location{X: origin.X + half, Y: origin.Y + half}))
+}
+
+// fromLocations creates the smallest node, centered on the origin, which contains all of the locations.
+// Returns the node and the location of its top-left corner.
+func (t *quadtree) fromLocations(living []Location, minLevel uint) (*quadNode, Location) {
+	level := minLevel
+	for _, loc := range living {
+		for !quadContains(level, loc) {
+			level++
+		}
+	}
+
+	half := 0
+	if level > 0 {
+		half = 1 << (level - 1)
+	}
+	origin := Location{X: -half, Y: -half}
+
+	return t.build(living, level, origin), origin
+}
+
+// quadContains tests if the node of the given level centered on the origin contains the location
+func quadContains(level uint, loc Location) bool {
+	if level == 0 {
+		return loc.X == 0 && loc.Y == 0
+	}
+	half := 1 << (level - 1)
+	return loc.X >= -half && loc.X < half && loc.Y >= -half && loc.Y < half
+}
+
+// locations returns the living cells of the node whose top-left corner is at the given origin
+func (t *quadtree) locations(n *quadNode, origin Location) []Location {
+	living := make([]Location, 0, n.population)
+	t.appendLocations(&living, n, origin)
+	return living
+}
+
+func (t *quadtree) appendLocations(living *[]Location, n *quadNode, origin Location) {
+	switch {
+	case n.population == 0:
+		return
+	case n.level == 0:
+		*living = append(*living, origin)
+		return
+	}
+
+	half := n.size() / 2
+	t.appendLocations(living, n.nw, origin)
+	t.appendLocations(living, n.ne, Location{X: origin.X + half, Y: origin.Y})
+	t.appendLocations(living, n.sw, Location{X: origin.X, Y: origin.Y + half})
+	t.appendLocations(living, n.se, Location{X: origin.X + half, Y: origin.Y + half})
+}
+
+func newQuadtree() *quadtree {
+	t := new(quadtree)
+
+	t.nodes = make(map[quadKey]*quadNode)
+	t.empty = []*quadNode{&quadNode{level: 0}}
+	t.alive = &quadNode{level: 0, population: 1}
+
+	return t
+}
+
+// vim: set foldmethod=marker:
@@ -0,0 +1,46 @@
+package life
+
+import "testing"
+
+func TestQuadtreeCanonicalNodes(t *testing.T) {
+	tree := newQuadtree()
+
+	first := tree.node(tree.alive, tree.emptyNode(0), tree.emptyNode(0), tree.alive)
+	second := tree.node(tree.alive, tree.emptyNode(0), tree.emptyNode(0), tree.alive)
+
+	if first != second {
+		t.Fatal("Identical nodes were not shared")
+	}
+
+	if first.level != 1 || first.population != 2 {
+		t.Fatalf("Node has level %d and population %d instead of 1 and 2\n", first.level, first.population)
+	}
+}
+
+func TestQuadtreeEmptyNode(t *testing.T) {
+	tree := newQuadtree()
+
+	empty := tree.emptyNode(10)
+	if empty.level != 10 || empty.population != 0 {
+		t.Fatalf("Empty node has level %d and population %d\n", empty.level, empty.population)
+	}
+
+	if empty.nw != tree.emptyNode(9) {
+		t.Fatal("Empty nodes were not shared")
+	}
+}
+
+func TestQuadtreeLocations(t *testing.T) {
+	tree := newQuadtree()
+
+	expected := Random(Dimensions{Width: 37, Height: 21}, Location{X: -20, Y: -3}, 50)
+
+	root, origin := tree.fromLocations(expected, 0)
+	if root.population != len(expected) {
+		t.Fatalf("Root has population %d instead of %d\n", root.population, len(expected))
+	}
+
+	testLocationsMatch(t, expected, tree.locations(root, origin))
+}
+
+// vim: set foldmethod=marker:
@@ -0,0 +1,248 @@
+package life
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// Maximum length of the lines of an RLE pattern body
+const rleLineLength = 70
+
+func parseRLEHeader(header string, pattern *PatternFile) error {
+	fields := strings.Split(header, ",")
+	for i, field := range fields {
+		keyval := strings.SplitN(field, "=", 2)
+		if len(keyval) != 2 {
+			return fmt.Errorf("malformed header field %q", strings.TrimSpace(field))
+		}
+
+		key := strings.ToLower(strings.TrimSpace(keyval[0]))
+		val := strings.TrimSpace(keyval[1])
+
+		switch key {
+		case "x", "y":
+			size, err := strconv.Atoi(val)
+			if err != nil || size < 0 {
+				return fmt.Errorf("invalid pattern %s size %q", key, val)
+			}
+			if key == "x" {
+				pattern.Dims.Width = size
+			} else {
+				pattern.Dims.Height = size
+			}
+		case "rule":
+			// Golly follows the rules with the bounded grid the pattern lives on, such as B3/S23:T100,100,
+			// whose commas would otherwise start new fields. The grid is not part of the rules.
+			val = strings.TrimSpace(strings.Join(append([]string{keyval[1]}, fields[i+1:]...), ","))
+			if suffix := strings.IndexByte(val, ':'); suffix >= 0 {
+				val = val[:suffix]
+			}
+
+			rules, err := ParseRules(val)
+			if err != nil {
+				return err
+			}
+			pattern.Rules = rules
+			return nil
+		}
+	}
+
+	return nil
+}
+
+// ReadRLE decodes a pattern in the Run Length Encoded format
+func ReadRLE(r io.Reader) (*PatternFile, error) {
+	pattern := &PatternFile{Living: make([]Location, 0)}
+
+	var origin Location
+	foundHeader := false
+	done := false
+	x, y, count := 0, 0, 0
+
+	scanner := bufio.NewScanner(r)
+	for lineNum := 1; scanner.Scan() && !done; lineNum++ {
+		line := strings.TrimSpace(scanner.Text())
+
+		switch {
+		case len(line) == 0:
+			continue
+		case strings.HasPrefix(line, "#"):
+			if len(line) < 2 {
+				continue
+			}
+			val := strings.TrimSpace(line[2:])
+			switch line[1] {
+			case 'N':
+				pattern.Name = val
+			case 'O':
+				pattern.Author = val
+			case 'C', 'c':
+				pattern.Comments = append(pattern.Comments, val)
+			case 'P', 'R':
+				if _, err := fmt.Sscanf(val, "%d %d", &origin.X, &origin.Y); err != nil {
+					return nil, fmt.Errorf("line %d: invalid pattern position %q", lineNum, val)
+				}
+			}
+			continue
+		case !foundHeader && strings.HasPrefix(strings.ToLower(line), "x"):
+			if err := parseRLEHeader(line, pattern); err != nil {
+				return nil, fmt.Errorf("line %d: %s", lineNum, err)
+			}
+			foundHeader = true
+			continue
+		}
+
+		for _, c := range line {
+			switch {
+			case c >= '0' && c <= '9':
+				count = (count * 10) + int(c-'0')
+				continue
+			case c == ' ' || c == '\t':
+				continue
+			case c == '!':
+				done = true
+			case c == '$':
+				y += runCount(count)
+				x = 0
+			case c == 'b' || c == '.':
+				x += runCount(count)
+			case (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z'):
+				for i := runCount(count); i > 0; i-- {
+					pattern.Living = append(pattern.Living, Location{X: origin.X + x, Y: origin.Y + y})
+					x++
+				}
+			default:
+				return nil, fmt.Errorf("line %d: unexpected character '%c' in pattern", lineNum, c)
+			}
+			count = 0
+
+			if done {
+				break
+			}
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	if !foundHeader {
+		_, pattern.Dims = BoundingBox(pattern.Living)
+	}
+
+	return pattern, nil
+}
+
+func runCount(count int) int {
+	if count == 0 {
+		return 1
+	}
+	return count
+}
+
+// rleWriter accumulates the tokens of an RLE body and wraps the lines
+type rleWriter struct {
+	buf  bytes.Buffer
+	line bytes.Buffer
+}
+
+func (t *rleWriter) writeRun(count int, tag byte) {
+	if count <= 0 {
+		return
+	}
+
+	var token bytes.Buffer
+	if count > 1 {
+		token.WriteString(strconv.Itoa(count))
+	}
+	token.WriteByte(tag)
+
+	if t.line.Len()+token.Len() > rleLineLength {
+		t.flush()
+	}
+	t.line.Write(token.Bytes())
+}
+
+func (t *rleWriter) flush() {
+	if t.line.Len() > 0 {
+		t.buf.Write(t.line.Bytes())
+		t.buf.WriteString("\n")
+		t.line.Reset()
+	}
+}
+
+// WriteRLE encodes the given pattern in the Run Length Encoded format.
+// The living cells of a Generation or a Life's Seed can be written by wrapping them in a PatternFile.
+func WriteRLE(w io.Writer, pattern *PatternFile) error {
+	var buf bytes.Buffer
+
+	if len(pattern.Name) > 0 {
+		buf.WriteString("#N " + pattern.Name + "\n")
+	}
+	if len(pattern.Author) > 0 {
+		buf.WriteString("#O " + pattern.Author + "\n")
+	}
+	for _, comment := range pattern.Comments {
+		buf.WriteString("#C " + comment + "\n")
+	}
+
+	origin, dims := BoundingBox(pattern.Living)
+	if origin.X != 0 || origin.Y != 0 {
+		buf.WriteString(fmt.Sprintf("#R %d %d\n", origin.X, origin.Y))
+	}
+
+	buf.WriteString(fmt.Sprintf("x = %d, y = %d", dims.Width, dims.Height))
+	if pattern.Rules != nil {
+		buf.WriteString(", rule = " + pattern.Rules.Rulestring())
+	}
+	buf.WriteString("\n")
+
+	// Organize the living cells by row
+	rows := make(map[int]map[int]bool)
+	for _, loc := range pattern.Living {
+		if _, keyExists := rows[loc.Y-origin.Y]; !keyExists {
+			rows[loc.Y-origin.Y] = make(map[int]bool)
+		}
+		rows[loc.Y-origin.Y][loc.X-origin.X] = true
+	}
+
+	body := new(rleWriter)
+	pendingRows := 0
+	for y := 0; y < dims.Height; y++ {
+		row, keyExists := rows[y]
+		if !keyExists {
+			pendingRows++
+			continue
+		}
+
+		body.writeRun(pendingRows, '$')
+		pendingRows = 1
+
+		dead, alive := 0, 0
+		for x := 0; x < dims.Width; x++ {
+			if row[x] {
+				body.writeRun(dead, 'b')
+				dead = 0
+				alive++
+			} else {
+				body.writeRun(alive, 'o')
+				alive = 0
+				dead++
+			}
+		}
+		body.writeRun(alive, 'o')
+	}
+	body.writeRun(1, '!')
+	body.flush()
+
+	buf.Write(body.buf.Bytes())
+
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+// vim: set foldmethod=marker:
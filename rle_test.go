@@ -0,0 +1,165 @@
+package life
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func testLocationsMatch(t *testing.T, expected, actual []Location) {
+	if len(actual) != len(expected) {
+		t.Fatalf("Retrieved %d locations but expected %d\n", len(actual), len(expected))
+	}
+
+	expectedTracker := newTracker()
+	for _, loc := range expected {
+		expectedTracker.Set(loc)
+	}
+
+	for _, loc := range actual {
+		if !expectedTracker.Test(loc) {
+			t.Fatalf("Found location %s which was not expected\n", loc.String())
+		}
+	}
+}
+
+func TestReadRLE(t *testing.T) {
+	rle := `#N Glider
+#O Richard K. Guy
+#C The smallest, most common, and first discovered spaceship.
+#C www.conwaylife.com/wiki/index.php?title=Glider
+x = 3, y = 3, rule = B3/S23
+bob$2bo$3o!`
+
+	pattern, err := ReadRLE(strings.NewReader(rle))
+	if err != nil {
+		t.Fatalf("Unable to read RLE: %s\n", err)
+	}
+
+	if pattern.Name != "Glider" {
+		t.Errorf("Read name %q instead of Glider\n", pattern.Name)
+	}
+	if pattern.Author != "Richard K. Guy" {
+		t.Errorf("Read author %q instead of Richard K. Guy\n", pattern.Author)
+	}
+	if len(pattern.Comments) != 2 {
+		t.Errorf("Read %d comments instead of 2\n", len(pattern.Comments))
+	}
+	if pattern.Rules == nil || pattern.Rules.Rulestring() != "B3/S23" {
+		t.Error("Did not read the expected rules")
+	}
+
+	expectedDims := Dimensions{Width: 3, Height: 3}
+	if !pattern.Dims.Equals(&expectedDims) {
+		t.Errorf("Read dimensions %s instead of %s\n", pattern.Dims.String(), expectedDims.String())
+	}
+
+	testLocationsMatch(t, Gliders(Dimensions{Width: 3, Height: 3}, Location{}), pattern.Living)
+}
+
+func TestReadRLEBoundedGrid(t *testing.T) {
+	for _, header := range []string{"x = 3, y = 3, rule = B3/S23:T100,100", "x = 3, y = 3, rule = B3/S23:P20,20"} {
+		pattern, err := ReadRLE(strings.NewReader(header + "\nbob$2bo$3o!"))
+		if err != nil {
+			t.Fatalf("Unable to read RLE with header %q: %s\n", header, err)
+		}
+		if pattern.Rules == nil || pattern.Rules.Rulestring() != "B3/S23" {
+			t.Errorf("Did not read the expected rules from header %q\n", header)
+		}
+		testLocationsMatch(t, Gliders(Dimensions{Width: 3, Height: 3}, Location{}), pattern.Living)
+	}
+}
+
+func TestReadRLEMultipleLines(t *testing.T) {
+	rle := `x = 15, y = 15
+3b3o3b3o2$
+o4bobo4bo$o4bobo4bo$o4bobo4bo$2b3o3b3o2$2b3o3b3o$o4bobo4bo$o4bobo4bo$o4bobo4bo2$
+2b3o3b3o!`
+
+	pattern, err := ReadRLE(strings.NewReader(rle))
+	if err != nil {
+		t.Fatalf("Unable to read RLE: %s\n", err)
+	}
+
+	if pattern.Rules != nil {
+		t.Error("Unexpectedly read rules when none were specified")
+	}
+
+	if len(pattern.Living) != 48 {
+		t.Fatalf("Read %d living cells instead of 48\n", len(pattern.Living))
+	}
+}
+
+func TestReadRLEError(t *testing.T) {
+	bogus := []string{
+		"x = 3, y = 3, rule = B9/S23\nbob$2bo$3o!",
+		"x = 3, y = three\nbob$2bo$3o!",
+		"x = 3, y = 3\nbob$2bo$3o*!",
+		"#P a b\nx = 3, y = 3\nbob$2bo$3o!",
+	}
+
+	for _, rle := range bogus {
+		if _, err := ReadRLE(strings.NewReader(rle)); err == nil {
+			t.Errorf("Unexpectedly read bogus RLE %q\n", rle)
+		}
+	}
+}
+
+func TestWriteRLE(t *testing.T) {
+	pattern := &PatternFile{
+		Name:     "Glider",
+		Comments: []string{"A comment"},
+		Rules:    GetConwayRules(),
+		Living:   Gliders(Dimensions{Width: 3, Height: 3}, Location{}),
+	}
+
+	var buf bytes.Buffer
+	if err := WriteRLE(&buf, pattern); err != nil {
+		t.Fatalf("Unable to write RLE: %s\n", err)
+	}
+
+	expected := "#N Glider\n#C A comment\nx = 3, y = 3, rule = B3/S23\nbo$2bo$3o!\n"
+	if buf.String() != expected {
+		t.Fatalf("Wrote RLE\n%s\ninstead of\n%s\n", buf.String(), expected)
+	}
+}
+
+func TestWriteRLELineLength(t *testing.T) {
+	// A checkerboard has no runs, so it produces the longest lines
+	living := getRepeatingPattern(Dimensions{Width: 100, Height: 4}, Dimensions{Width: 1, Height: 1}, Location{},
+		func(seed *[]Location, currentX, currentY int) {
+			if (currentX+currentY)%2 == 0 {
+				*seed = append(*seed, Location{X: currentX, Y: currentY})
+			}
+		})
+
+	var buf bytes.Buffer
+	if err := WriteRLE(&buf, &PatternFile{Living: living}); err != nil {
+		t.Fatalf("Unable to write RLE: %s\n", err)
+	}
+
+	for _, line := range strings.Split(buf.String(), "\n") {
+		if len(line) > rleLineLength {
+			t.Fatalf("Line is %d characters long: %s\n", len(line), line)
+		}
+	}
+}
+
+func TestRLERoundTrip(t *testing.T) {
+	size := Dimensions{Width: 32, Height: 32}
+	expected := Random(size, Location{X: -5, Y: 3}, 40)
+
+	var buf bytes.Buffer
+	if err := WriteRLE(&buf, &PatternFile{Living: expected}); err != nil {
+		t.Fatalf("Unable to write RLE: %s\n", err)
+	}
+
+	pattern, err := ReadRLE(&buf)
+	if err != nil {
+		t.Fatalf("Unable to read RLE: %s\n", err)
+	}
+
+	testLocationsMatch(t, expected, pattern.Living)
+}
+
+// vim: set foldmethod=marker:
@@ -2,31 +2,252 @@ package life
 
 import (
 	"bytes"
+	"errors"
+	"fmt"
+	"sort"
 	"strconv"
+	"strings"
+	"unicode"
 )
 
-// Rules encapsulates the standard Life rules
+// Rules encapsulates the standard Life rules, those of the Generations family and isotropic non-totalistic rules
 type Rules struct {
 	Survive []int // The number of neighbors an alive cell needs to have to survive
 	Born    []int // The number of neighbors a dead cell needs to have to be born
+	States  int   // The number of states of the Generations family. Zero for rules where cells are only alive or dead
+
+	// The Hensel letters of the configurations of neighbors which a number of neighbors is restricted to.
+	// Numbers of neighbors without letters are not restricted. Both are nil for totalistic rules.
+	SurviveLetters map[int]string
+	BornLetters    map[int]string
+}
+
+// Totalistic tests if the rules only depend on the number of neighbors, and not on their configuration
+func (t *Rules) Totalistic() bool {
+	return len(t.SurviveLetters) == 0 && len(t.BornLetters) == 0
+}
+
+// writeRuleCount writes the number of neighbors followed by the Hensel letters it is restricted to, if any.
+// When more than half of the letters are allowed, the ones which are not are written after a '-' instead.
+func writeRuleCount(buf *bytes.Buffer, val int, letters map[int]string) {
+	buf.WriteString(strconv.Itoa(val))
+
+	allowed, restricted := letters[val]
+	if !restricted {
+		return
+	}
+
+	var excluded string
+	for _, letter := range henselOrder[val] {
+		if !strings.ContainsRune(allowed, letter) {
+			excluded += string(letter)
+		}
+	}
+
+	if len(excluded) < len(allowed) {
+		buf.WriteString("-")
+		buf.WriteString(excluded)
+	} else {
+		buf.WriteString(allowed)
+	}
 }
 
 func (t *Rules) String() string {
 	var buf bytes.Buffer
 
 	for _, val := range t.Survive {
-		buf.WriteString(strconv.Itoa(val))
+		writeRuleCount(&buf, val, t.SurviveLetters)
 	}
 
 	buf.WriteString("/")
 
 	for _, val := range t.Born {
-		buf.WriteString(strconv.Itoa(val))
+		writeRuleCount(&buf, val, t.BornLetters)
+	}
+
+	if t.States > 2 {
+		buf.WriteString("/")
+		buf.WriteString(strconv.Itoa(t.States))
+	}
+
+	return buf.String()
+}
+
+// Rulestring returns the rules in the canonical B/S notation (e.g. "B3/S23" or "B2-a/S12")
+func (t *Rules) Rulestring() string {
+	var buf bytes.Buffer
+
+	buf.WriteString("B")
+	for _, val := range sortedRuleCounts(t.Born) {
+		writeRuleCount(&buf, val, t.BornLetters)
+	}
+
+	buf.WriteString("/S")
+	for _, val := range sortedRuleCounts(t.Survive) {
+		writeRuleCount(&buf, val, t.SurviveLetters)
+	}
+
+	if t.States > 2 {
+		buf.WriteString("/C")
+		buf.WriteString(strconv.Itoa(t.States))
 	}
 
 	return buf.String()
 }
 
+func sortedRuleCounts(counts []int) []int {
+	sorted := make([]int, len(counts))
+	copy(sorted, counts)
+	sort.Ints(sorted)
+	return sorted
+}
+
+// parseRuleCounts parses the numbers of neighbors of a section of a rulestring. In Hensel notation, each number can
+// be followed by the letters of the only configurations of neighbors it applies to, or by a '-' and the letters of
+// the configurations it does not apply to. The letters of the numbers which are restricted are returned in their
+// canonical order, or nil if none are.
+func parseRuleCounts(counts string) ([]int, map[int]string, error) {
+	parsed := make([]int, 0)
+	var letters map[int]string
+	seen := make(map[int]bool)
+
+	for i := 0; i < len(counts); {
+		c := counts[i]
+		if c < '0' || c > '8' {
+			return nil, nil, fmt.Errorf("invalid neighbor count '%c'", c)
+		}
+		i++
+
+		val := int(c - '0')
+		if seen[val] {
+			return nil, nil, fmt.Errorf("neighbor count %d is repeated", val)
+		}
+		seen[val] = true
+
+		negated := i < len(counts) && counts[i] == '-'
+		if negated {
+			i++
+		}
+
+		given := make(map[rune]bool)
+		for ; i < len(counts) && (counts[i] < '0' || counts[i] > '8'); i++ {
+			letter := unicode.ToLower(rune(counts[i]))
+			if !strings.ContainsRune(henselOrder[val], letter) {
+				return nil, nil, fmt.Errorf("'%c' is not a configuration of %d neighbors", letter, val)
+			}
+			if given[letter] {
+				return nil, nil, fmt.Errorf("configuration %d%c is repeated", val, letter)
+			}
+			given[letter] = true
+		}
+
+		if len(given) == 0 {
+			if negated {
+				return nil, nil, fmt.Errorf("neighbor count %d is missing the configurations after '-'", val)
+			}
+			parsed = append(parsed, val)
+			continue
+		}
+
+		var allowed string
+		for _, letter := range henselOrder[val] {
+			if given[letter] != negated {
+				allowed += string(letter)
+			}
+		}
+
+		switch allowed {
+		case "":
+			// None of the configurations apply
+		case henselOrder[val]:
+			parsed = append(parsed, val)
+		default:
+			if letters == nil {
+				letters = make(map[int]string)
+			}
+			letters[val] = allowed
+			parsed = append(parsed, val)
+		}
+	}
+
+	return parsed, letters, nil
+}
+
+// ParseRules creates a Rules struct from the given rulestring.
+// Both the B/S notation ("B3/S23" or "S23/B3") and the
+// older survival/birth notation ("23/3") are accepted.
+// Rules of the Generations family add the number of states as
+// a third section ("B2/S345/C4" or "345/2/4").
+// Isotropic non-totalistic rules are given in Hensel notation ("B2-a/S12").
+func ParseRules(rulestring string) (*Rules, error) {
+	normalized := strings.ToUpper(strings.TrimSpace(rulestring))
+	if len(normalized) == 0 {
+		return nil, errors.New("rulestring is empty")
+	}
+
+	parts := strings.Split(normalized, "/")
+	if len(parts) != 2 && len(parts) != 3 {
+		return nil, fmt.Errorf("rulestring %q must have two or three sections separated by '/'", rulestring)
+	}
+
+	states := 0
+	if len(parts) == 3 {
+		count := parts[2]
+		if strings.HasPrefix(count, "C") || strings.HasPrefix(count, "G") {
+			count = count[1:]
+		}
+		val, err := strconv.Atoi(count)
+		if err != nil || val < 2 {
+			return nil, fmt.Errorf("rulestring %q has an invalid number of states", rulestring)
+		}
+		states = val
+		parts = parts[:2]
+	}
+
+	var born, survive string
+	var foundBorn, foundSurvive bool
+
+	for i, part := range parts {
+		switch {
+		case strings.HasPrefix(part, "B"):
+			if foundBorn {
+				return nil, fmt.Errorf("rulestring %q has more than one birth section", rulestring)
+			}
+			born = part[1:]
+			foundBorn = true
+		case strings.HasPrefix(part, "S"):
+			if foundSurvive {
+				return nil, fmt.Errorf("rulestring %q has more than one survival section", rulestring)
+			}
+			survive = part[1:]
+			foundSurvive = true
+		case foundBorn || foundSurvive:
+			return nil, fmt.Errorf("rulestring %q mixes B/S and survival/birth notation", rulestring)
+		case i == 0:
+			survive = part
+		default:
+			born = part
+		}
+	}
+
+	if (foundBorn || foundSurvive) && !(foundBorn && foundSurvive) {
+		return nil, fmt.Errorf("rulestring %q mixes B/S and survival/birth notation", rulestring)
+	}
+
+	rules := new(Rules)
+	rules.States = states
+
+	var err error
+	if rules.Born, rules.BornLetters, err = parseRuleCounts(born); err != nil {
+		return nil, fmt.Errorf("rulestring %q has an invalid birth section: %s", rulestring, err)
+	}
+	if rules.Survive, rules.SurviveLetters, err = parseRuleCounts(survive); err != nil {
+		return nil, fmt.Errorf("rulestring %q has an invalid survival section: %s", rulestring, err)
+	}
+
+	return rules, nil
+}
+
 // This function tests the number of neighbors that a cell has against
 // the rules given. If the cell lives (or continues to live) based on the
 // rules, then the function returns true.
@@ -45,13 +266,39 @@ func testRule(numNeighbors int, isAlive bool, rules *Rules) bool {
 	return false
 }
 
-// RulesTester returns a RulesTest function that uses the given ruleset
+// RulesTester returns a RulesTest function that uses the given ruleset.
+// It only knows the number of neighbors, so rules which are not totalistic need a NeighborhoodTester.
 func RulesTester(rules *Rules) func(int, bool) bool {
 	return func(numNeighbors int, isAlive bool) bool {
 		return testRule(numNeighbors, isAlive, rules)
 	}
 }
 
+// This function tests the configuration of the neighbors that a cell has
+// against the rules given, including the Hensel letters they are restricted to.
+func testNeighborhood(neighborhood Neighborhood, isAlive bool, rules *Rules) bool {
+	count := neighborhood.Count()
+	if !testRule(count, isAlive, rules) {
+		return false
+	}
+
+	letters := rules.SurviveLetters
+	if !isAlive {
+		letters = rules.BornLetters
+	}
+
+	allowed, restricted := letters[count]
+	return !restricted || strings.IndexByte(allowed, neighborhood.Letter()) >= 0
+}
+
+// NeighborhoodTester returns a function which tests the configuration of the neighbors of a cell against the given
+// ruleset, which can be isotropic non-totalistic. It is given to a Life with SetNeighborhoodRules.
+func NeighborhoodTester(rules *Rules) func(Neighborhood, bool) bool {
+	return func(neighborhood Neighborhood, isAlive bool) bool {
+		return testNeighborhood(neighborhood, isAlive, rules)
+	}
+}
+
 // GetConwayRules returns a Rules struct filled with the normal Conway rules of 23/3
 //	-- Rules --
 // 	1. If live cell has < 2 neighbors, it dies
@@ -3,7 +3,7 @@ package life
 import "testing"
 
 func TestRulesString(t *testing.T) {
-	rules := &Rules{[]int{1, 2, 3}, []int{4, 5}}
+	rules := &Rules{Survive: []int{1, 2, 3}, Born: []int{4, 5}}
 
 	if len(rules.String()) <= 0 {
 		t.Error("Rules unexpectedly returned empty string")
@@ -47,4 +47,142 @@ func TestRuleRulesConway(t *testing.T) {
 	}
 }
 
+func TestRulesRulestring(t *testing.T) {
+	rules := &Rules{Survive: []int{3, 2}, Born: []int{6, 3}}
+
+	if rules.Rulestring() != "B36/S23" {
+		t.Fatalf("Retrieved rulestring %s instead of B36/S23\n", rules.Rulestring())
+	}
+}
+
+func TestParseRules(t *testing.T) {
+	tests := []struct {
+		rulestring string
+		expected   string
+	}{
+		{"B3/S23", "B3/S23"},
+		{"S23/B3", "B3/S23"},
+		{"23/3", "B3/S23"},
+		{"b36/s23", "B36/S23"},
+		{"B2/S", "B2/S"},
+		{"/2", "B2/S"},
+		{" B3678/S34678 ", "B3678/S34678"},
+		{"/2/3", "B2/S/C3"},
+		{"345/2/4", "B2/S345/C4"},
+		{"B2/S345/C4", "B2/S345/C4"},
+		{"b2/s345/g4", "B2/S345/C4"},
+		{"23/3/2", "B3/S23"},
+		{"B2-a/S12", "B2-a/S12"},
+		{"b3/s2-i34q", "B3/S2-i34q"},
+		{"B2CEIKN/S12", "B2-a/S12"},
+		{"B2ceaikn/S23", "B2/S23"},
+		{"B3/S2-ceaikn3", "B3/S3"},
+		{"B3ia2e/S", "B2e3ai/S"},
+		{"2-i34q/3", "B3/S2-i34q"},
+	}
+
+	for _, test := range tests {
+		rules, err := ParseRules(test.rulestring)
+		if err != nil {
+			t.Fatalf("Unable to parse rulestring %s: %s\n", test.rulestring, err)
+		}
+
+		if rules.Rulestring() != test.expected {
+			t.Errorf("Parsed %s as %s instead of %s\n", test.rulestring, rules.Rulestring(), test.expected)
+		}
+	}
+}
+
+func TestParseRulesRoundTrip(t *testing.T) {
+	expected := GetConwayRules()
+
+	actual, err := ParseRules(expected.Rulestring())
+	if err != nil {
+		t.Fatalf("Unable to parse rulestring: %s\n", err)
+	}
+
+	if actual.String() != expected.String() {
+		t.Fatalf("Round trip produced %s instead of %s\n", actual.String(), expected.String())
+	}
+}
+
+func TestRulesGenerationsString(t *testing.T) {
+	rules := &Rules{Survive: []int{3, 4, 5}, Born: []int{2}, States: 4}
+
+	if rules.String() != "345/2/4" {
+		t.Errorf("Rules are %s instead of 345/2/4\n", rules.String())
+	}
+
+	actual, err := ParseRules(rules.Rulestring())
+	if err != nil {
+		t.Fatalf("Unable to parse rulestring: %s\n", err)
+	}
+	if actual.States != rules.States || actual.Rulestring() != rules.Rulestring() {
+		t.Fatalf("Round trip produced %s instead of %s\n", actual.Rulestring(), rules.Rulestring())
+	}
+}
+
+func TestRulesHenselString(t *testing.T) {
+	rules, err := ParseRules("B2-a/S12")
+	if err != nil {
+		t.Fatalf("Unable to parse rulestring: %s\n", err)
+	}
+	if rules.Totalistic() {
+		t.Fatal("Rules with Hensel letters are totalistic")
+	}
+	if rules.String() != "12/2-a" {
+		t.Fatalf("Rules are %s instead of 12/2-a\n", rules.String())
+	}
+
+	if !GetConwayRules().Totalistic() {
+		t.Fatal("Conway rules are not totalistic")
+	}
+}
+
+func TestNeighborhoodTester(t *testing.T) {
+	// Allowing every configuration is the same as only counting the neighbors
+	everything, err := ParseRules("B3ceaiknjqry/S2ceaikn3ceaiknjqry")
+	if err != nil {
+		t.Fatalf("Unable to parse rulestring: %s\n", err)
+	}
+	conway := NeighborhoodTester(everything)
+	letters := NeighborhoodTester(&Rules{Survive: []int{2, 3}, Born: []int{3},
+		SurviveLetters: map[int]string{2: "ceaikn", 3: "ceaiknjqry"}, BornLetters: map[int]string{3: "ceaiknjqry"}})
+
+	rules, err := ParseRules("B2-a/S12")
+	if err != nil {
+		t.Fatalf("Unable to parse rulestring: %s\n", err)
+	}
+	tester := NeighborhoodTester(rules)
+
+	for n := 0; n < 256; n++ {
+		neighborhood := Neighborhood(n)
+		for _, alive := range []bool{true, false} {
+			expected := ConwayTester()(neighborhood.Count(), alive)
+			if conway(neighborhood, alive) != expected || letters(neighborhood, alive) != expected {
+				t.Fatalf("Configuration %08b of an organism which is alive (%t) does not follow Conway's rules\n", n, alive)
+			}
+		}
+
+		count := neighborhood.Count()
+		if born := tester(neighborhood, false); born != (count == 2 && neighborhood.Letter() != 'a') {
+			t.Fatalf("Organism with the configuration %d%c being born is %t\n", count, neighborhood.Letter(), born)
+		}
+		if survived := tester(neighborhood, true); survived != (count == 1 || count == 2) {
+			t.Fatalf("Organism with the configuration %d%c surviving is %t\n", count, neighborhood.Letter(), survived)
+		}
+	}
+}
+
+func TestParseRulesError(t *testing.T) {
+	bogus := []string{"", "B3", "B3/S23/C1", "B3/S23/Cx", "B3/S23/C", "B3/S23/C3/C3", "B3/23", "23/B3", "B3/B3", "B9/S23", "B3/S2x", "B33/S23",
+		"B2-/S23", "B2a-a/S", "B8c/S", "B0a/S", "B2aa/S", "B1k/S", "B2-a2c/S"}
+
+	for _, rulestring := range bogus {
+		if _, err := ParseRules(rulestring); err == nil {
+			t.Errorf("Unexpectedly parsed bogus rulestring %q\n", rulestring)
+		}
+	}
+}
+
 // vim: set foldmethod=marker:
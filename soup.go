@@ -0,0 +1,189 @@
+package life
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"strings"
+)
+
+type soupSymmetry int
+
+// Enumeration of the symmetries a soup can be generated with
+const (
+	SoupAsymmetric soupSymmetry = iota
+	SoupC2                      // Unchanged by a half turn
+	SoupC4                      // Unchanged by a quarter turn
+	SoupD2                      // Mirrored left to right
+	SoupD4                      // Mirrored left to right and top to bottom
+	SoupD8                      // Unchanged by any rotation or reflection of a square
+)
+
+func (t soupSymmetry) String() string {
+	switch t {
+	case SoupAsymmetric:
+		return "C1"
+	case SoupC2:
+		return "C2"
+	case SoupC4:
+		return "C4"
+	case SoupD2:
+		return "D2"
+	case SoupD4:
+		return "D4"
+	case SoupD8:
+		return "D8"
+	}
+	return "Unknown"
+}
+
+// ParseSoupSymmetry returns the soup symmetry whose String() matches the given name
+func ParseSoupSymmetry(name string) (soupSymmetry, error) {
+	for t := SoupAsymmetric; t <= SoupD8; t++ {
+		if strings.EqualFold(t.String(), name) {
+			return t, nil
+		}
+	}
+	return SoupAsymmetric, fmt.Errorf("Did not recognize soup symmetry %q", name)
+}
+
+// area returns the size of the area which is filled by a soup of the given board size.
+// The symmetries which include quarter turns and diagonal reflections only fill the largest square which fits.
+func (t soupSymmetry) area(dimensions Dimensions) Dimensions {
+	switch t {
+	case SoupC4, SoupD8:
+		side := dimensions.Width
+		if dimensions.Height < side {
+			side = dimensions.Height
+		}
+		return Dimensions{Width: side, Height: side}
+	}
+	return dimensions
+}
+
+// images returns the locations which the given location is mapped to by the symmetry, including itself
+func (t soupSymmetry) images(loc Location, dims Dimensions) []Location {
+	right, bottom := dims.Width-1, dims.Height-1
+	switch t {
+	case SoupC2:
+		return []Location{loc, {X: right - loc.X, Y: bottom - loc.Y}}
+	case SoupC4:
+		return []Location{loc, {X: right - loc.Y, Y: loc.X}, {X: right - loc.X, Y: bottom - loc.Y}, {X: loc.Y, Y: bottom - loc.X}}
+	case SoupD2:
+		return []Location{loc, {X: right - loc.X, Y: loc.Y}}
+	case SoupD4:
+		return []Location{loc, {X: right - loc.X, Y: loc.Y}, {X: loc.X, Y: bottom - loc.Y}, {X: right - loc.X, Y: bottom - loc.Y}}
+	case SoupD8:
+		return []Location{loc, {X: right - loc.Y, Y: loc.X}, {X: right - loc.X, Y: bottom - loc.Y}, {X: loc.Y, Y: bottom - loc.X},
+			{X: loc.Y, Y: loc.X}, {X: right - loc.X, Y: loc.Y}, {X: loc.X, Y: bottom - loc.Y}, {X: right - loc.Y, Y: bottom - loc.X}}
+	}
+	return []Location{loc}
+}
+
+// densitySoup decides randomly if each group of symmetric locations is alive, with the probability
+// returned by the density function for the first location of the group
+func densitySoup(dimensions Dimensions, offset Location, symmetry soupSymmetry, rng *rand.Rand, density func(Location) float64) []Location {
+	dims := symmetry.area(dimensions)
+
+	seed := make([]Location, 0)
+	for y := 0; y < dims.Height; y++ {
+		for x := 0; x < dims.Width; x++ {
+			loc := Location{X: x, Y: y}
+
+			// Only the first location of each group decides the fate of the whole group
+			images := symmetry.images(loc, dims)
+			first := true
+			for _, image := range images {
+				if image.Y < y || (image.Y == y && image.X < x) {
+					first = false
+					break
+				}
+			}
+			if !first || rng.Float64() >= density(loc) {
+				continue
+			}
+
+			added := make(map[Location]bool, len(images))
+			for _, image := range images {
+				if !added[image] {
+					added[image] = true
+					seed = append(seed, Location{X: image.X + offset.X, Y: image.Y + offset.Y})
+				}
+			}
+		}
+	}
+
+	return seed
+}
+
+// Soup randomly fills the board so that about the given percent of it is alive, with the given symmetry.
+// The same source of random numbers always generates the same soup.
+func Soup(dimensions Dimensions, offset Location, percent int, symmetry soupSymmetry, rng *rand.Rand) []Location {
+	return densitySoup(dimensions, offset, symmetry, rng, func(Location) float64 {
+		return float64(percent) / 100
+	})
+}
+
+// GaussianSoup randomly fills the board with organisms which are most dense at its center, where the given
+// percent of it is alive. The density falls off as a normal distribution with the given standard deviation,
+// which must be positive or the soup is empty.
+func GaussianSoup(dimensions Dimensions, offset Location, percent int, deviation float64, symmetry soupSymmetry, rng *rand.Rand) []Location {
+	if deviation <= 0 {
+		return []Location{}
+	}
+
+	dims := symmetry.area(dimensions)
+	center := []Location{{X: dims.Width / 2, Y: dims.Height / 2}}
+	return densitySoup(dimensions, offset, symmetry, rng, gaussianDensity(center, percent, deviation))
+}
+
+// ClusteredSoup randomly fills the board with the given number of clusters of organisms around random centers.
+// Each cluster is as dense as a GaussianSoup with the given percent and standard deviation.
+func ClusteredSoup(dimensions Dimensions, offset Location, percent, clusters int, deviation float64, rng *rand.Rand) []Location {
+	if dimensions.Width <= 0 || dimensions.Height <= 0 || clusters <= 0 || deviation <= 0 {
+		return []Location{}
+	}
+
+	centers := make([]Location, clusters)
+	for i := range centers {
+		centers[i] = Location{X: rng.Intn(dimensions.Width), Y: rng.Intn(dimensions.Height)}
+	}
+	return densitySoup(dimensions, offset, SoupAsymmetric, rng, gaussianDensity(centers, percent, deviation))
+}
+
+// gaussianDensity returns a density function which is at its peak at each of the centers
+func gaussianDensity(centers []Location, percent int, deviation float64) func(Location) float64 {
+	return func(loc Location) float64 {
+		var density float64
+		for _, center := range centers {
+			dx, dy := float64(loc.X-center.X), float64(loc.Y-center.Y)
+			density = math.Max(density, math.Exp(-((dx*dx)+(dy*dy))/(2*deviation*deviation)))
+		}
+		return density * float64(percent) / 100
+	}
+}
+
+// FixedSoup places exactly the given number of organisms at random locations of the board,
+// or fills the board if it does not have room for that many
+func FixedSoup(dimensions Dimensions, offset Location, count int, rng *rand.Rand) []Location {
+	if dimensions.Width <= 0 || dimensions.Height <= 0 || count <= 0 {
+		return []Location{}
+	}
+	if count > dimensions.Capacity() {
+		count = dimensions.Capacity()
+	}
+
+	seed := make([]Location, 0, count)
+	for _, cell := range rng.Perm(dimensions.Capacity())[:count] {
+		seed = append(seed, Location{X: (cell % dimensions.Width) + offset.X, Y: (cell / dimensions.Width) + offset.Y})
+	}
+
+	return seed
+}
+
+// SeededRandom generates the same random pattern as Random every time it is given the same seed
+func SeededRandom(dimensions Dimensions, offset Location, percent int, seed int64) []Location {
+	return Soup(dimensions, offset, percent, SoupAsymmetric, rand.New(rand.NewSource(seed)))
+}
+
+// vim: set foldmethod=marker:
@@ -0,0 +1,177 @@
+package life
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestSoupSymmetryString(t *testing.T) {
+	for s := SoupAsymmetric; s <= SoupD8; s++ {
+		parsed, err := ParseSoupSymmetry(s.String())
+		if err != nil {
+			t.Fatalf("Unable to parse symmetry %s: %s\n", s.String(), err)
+		}
+		if parsed != s {
+			t.Fatalf("Parsed %s as %s\n", s.String(), parsed.String())
+		}
+	}
+
+	if soupSymmetry(100).String() != "Unknown" {
+		t.Error("Invalid symmetry has a name")
+	}
+	if _, err := ParseSoupSymmetry("D6"); err == nil {
+		t.Error("Parsed a symmetry which does not exist")
+	}
+}
+
+func TestSoupReproducible(t *testing.T) {
+	size := Dimensions{Width: 40, Height: 30}
+
+	expected := SeededRandom(size, Location{X: 3, Y: -2}, 40, 1234)
+	testLocationsMatch(t, expected, SeededRandom(size, Location{X: 3, Y: -2}, 40, 1234))
+	testLocationsMatch(t, expected, Soup(size, Location{X: 3, Y: -2}, 40, SoupAsymmetric, rand.New(rand.NewSource(1234))))
+
+	if different := SeededRandom(size, Location{X: 3, Y: -2}, 40, 4321); canonicalKey(different) == canonicalKey(expected) {
+		t.Fatal("Different seeds generated the same soup")
+	}
+
+	testLocationsMatch(t,
+		ClusteredSoup(size, Location{}, 80, 3, 4, rand.New(rand.NewSource(99))),
+		ClusteredSoup(size, Location{}, 80, 3, 4, rand.New(rand.NewSource(99))))
+	testLocationsMatch(t,
+		FixedSoup(size, Location{}, 100, rand.New(rand.NewSource(99))),
+		FixedSoup(size, Location{}, 100, rand.New(rand.NewSource(99))))
+}
+
+func TestSoupDensity(t *testing.T) {
+	size := Dimensions{Width: 100, Height: 100}
+	rng := rand.New(rand.NewSource(7))
+
+	if living := Soup(size, Location{}, 0, SoupAsymmetric, rng); len(living) != 0 {
+		t.Fatalf("Empty soup has %d organisms\n", len(living))
+	}
+	if living := Soup(size, Location{}, 100, SoupD8, rng); len(living) != size.Capacity() {
+		t.Fatalf("Full soup has %d organisms instead of %d\n", len(living), size.Capacity())
+	}
+
+	for s := SoupAsymmetric; s <= SoupD8; s++ {
+		if living := Soup(size, Location{}, 50, s, rng); len(living) < 4000 || len(living) > 6000 {
+			t.Fatalf("Soup with %s symmetry that should be half full has %d organisms\n", s.String(), len(living))
+		}
+	}
+}
+
+func TestSoupSymmetric(t *testing.T) {
+	size := Dimensions{Width: 21, Height: 16}
+	offset := Location{X: -4, Y: 9}
+
+	for s := SoupAsymmetric; s <= SoupD8; s++ {
+		living := Soup(size, offset, 30, s, rand.New(rand.NewSource(int64(s))))
+		area := s.area(size)
+
+		alive := make(map[Location]bool)
+		for _, loc := range living {
+			relative := Location{X: loc.X - offset.X, Y: loc.Y - offset.Y}
+			if relative.X < 0 || relative.X >= area.Width || relative.Y < 0 || relative.Y >= area.Height {
+				t.Fatalf("Soup with %s symmetry has organism %s outside of its %s area\n", s.String(), loc.String(), area.String())
+			}
+			if alive[relative] {
+				t.Fatalf("Soup with %s symmetry has organism %s more than once\n", s.String(), loc.String())
+			}
+			alive[relative] = true
+		}
+
+		for loc := range alive {
+			for _, image := range s.images(loc, area) {
+				if !alive[image] {
+					t.Fatalf("Soup with %s symmetry has organism %s but not %s\n", s.String(), loc.String(), image.String())
+				}
+			}
+		}
+	}
+}
+
+func TestGaussianSoup(t *testing.T) {
+	size := Dimensions{Width: 60, Height: 60}
+	living := GaussianSoup(size, Location{}, 100, 6, SoupC4, rand.New(rand.NewSource(3)))
+
+	center, edges := 0, 0
+	for _, loc := range living {
+		switch {
+		case loc.X >= 25 && loc.X < 35 && loc.Y >= 25 && loc.Y < 35:
+			center++
+		case loc.X < 10 || loc.X >= 50 || loc.Y < 10 || loc.Y >= 50:
+			edges++
+		}
+	}
+	if center < 80 || edges > 0 {
+		t.Fatalf("Soup has %d organisms at its center and %d at its edges\n", center, edges)
+	}
+
+	for _, deviation := range []float64{0, -6} {
+		if living := GaussianSoup(size, Location{}, 100, deviation, SoupC4, rand.New(rand.NewSource(3))); len(living) != 0 {
+			t.Fatalf("Soup with a deviation of %g has %d organisms\n", deviation, len(living))
+		}
+	}
+}
+
+func TestClusteredSoup(t *testing.T) {
+	size := Dimensions{Width: 80, Height: 50}
+	living := ClusteredSoup(size, Location{X: 10, Y: 10}, 60, 4, 3, rand.New(rand.NewSource(11)))
+	if len(living) == 0 {
+		t.Fatal("Clustered soup is empty")
+	}
+
+	origin, dims := BoundingBox(living)
+	if origin.X < 10 || origin.Y < 10 || origin.X+dims.Width > 90 || origin.Y+dims.Height > 60 {
+		t.Fatalf("Clustered soup of %s at %s does not fit on the board\n", dims.String(), origin.String())
+	}
+
+	if living := ClusteredSoup(Dimensions{}, Location{}, 60, 4, 3, rand.New(rand.NewSource(11))); len(living) != 0 {
+		t.Fatalf("Clustered soup on an empty board has %d organisms\n", len(living))
+	}
+	if living := ClusteredSoup(size, Location{}, 60, 4, 0, rand.New(rand.NewSource(11))); len(living) != 0 {
+		t.Fatalf("Clustered soup without a deviation has %d organisms\n", len(living))
+	}
+	if living := ClusteredSoup(size, Location{}, 60, -4, 3, rand.New(rand.NewSource(11))); len(living) != 0 {
+		t.Fatalf("Clustered soup with a negative number of clusters has %d organisms\n", len(living))
+	}
+	if living := ClusteredSoup(Dimensions{Width: -80, Height: -50}, Location{}, 60, 4, 3, rand.New(rand.NewSource(11))); len(living) != 0 {
+		t.Fatalf("Clustered soup on a board of negative size has %d organisms\n", len(living))
+	}
+}
+
+func TestFixedSoup(t *testing.T) {
+	size := Dimensions{Width: 12, Height: 7}
+	rng := rand.New(rand.NewSource(5))
+
+	living := FixedSoup(size, Location{X: 2, Y: 2}, 30, rng)
+	if len(living) != 30 {
+		t.Fatalf("Soup has %d organisms instead of 30\n", len(living))
+	}
+
+	unique := make(map[Location]bool)
+	for _, loc := range living {
+		if loc.X < 2 || loc.X >= 14 || loc.Y < 2 || loc.Y >= 9 {
+			t.Fatalf("Organism %s is not on the board\n", loc.String())
+		}
+		unique[loc] = true
+	}
+	if len(unique) != len(living) {
+		t.Fatalf("Soup placed %d organisms on only %d locations\n", len(living), len(unique))
+	}
+
+	if living := FixedSoup(size, Location{}, 1000, rng); len(living) != size.Capacity() {
+		t.Fatalf("Soup with too many organisms has %d instead of %d\n", len(living), size.Capacity())
+	}
+	if living := FixedSoup(size, Location{}, -3, rng); len(living) != 0 {
+		t.Fatalf("Soup with a negative count has %d organisms\n", len(living))
+	}
+	for _, dims := range []Dimensions{{Width: -12, Height: -7}, {Width: -12, Height: 7}, {Width: 12, Height: 0}} {
+		if living := FixedSoup(dims, Location{}, 30, rng); len(living) != 0 {
+			t.Fatalf("Soup on a board of %s has %d organisms\n", dims.String(), len(living))
+		}
+	}
+}
+
+// vim: set foldmethod=marker:
@@ -0,0 +1,132 @@
+package life
+
+import (
+	"bytes"
+	"strconv"
+)
+
+// Velocity is how far a spaceship moves over the number of generations it takes to return to its shape
+type Velocity struct {
+	Displacement Location
+	Period       int
+}
+
+func gcd(a, b int) int {
+	for b != 0 {
+		a, b = b, a%b
+	}
+	return a
+}
+
+func abs(val int) int {
+	if val < 0 {
+		return -val
+	}
+	return val
+}
+
+// speed returns the given fraction of the speed of light in the conventional notation, such as 2c/5
+func speed(distance, period int) string {
+	if divisor := gcd(distance, period); divisor > 1 {
+		distance /= divisor
+		period /= divisor
+	}
+
+	var buf bytes.Buffer
+	if distance != 1 {
+		buf.WriteString(strconv.Itoa(distance))
+	}
+	buf.WriteString("c")
+	if period != 1 {
+		buf.WriteString("/")
+		buf.WriteString(strconv.Itoa(period))
+	}
+	return buf.String()
+}
+
+// Direction returns whether the spaceship moves orthogonally, diagonally or obliquely
+func (t Velocity) Direction() string {
+	dx, dy := abs(t.Displacement.X), abs(t.Displacement.Y)
+	switch {
+	case dx == 0 && dy == 0:
+		return "stationary"
+	case dx == 0 || dy == 0:
+		return "orthogonal"
+	case dx == dy:
+		return "diagonal"
+	}
+	return "oblique"
+}
+
+// String returns the velocity in the conventional notation, such as "c/4 diagonal", "2c/5 orthogonal" or "(2,1)c/6"
+func (t Velocity) String() string {
+	dx, dy := abs(t.Displacement.X), abs(t.Displacement.Y)
+	if dx < dy {
+		dx, dy = dy, dx
+	}
+
+	switch {
+	case dx == 0:
+		return "stationary"
+	case dy == 0:
+		return speed(dx, t.Period) + " orthogonal"
+	case dx == dy:
+		return speed(dx, t.Period) + " diagonal"
+	}
+	return "(" + strconv.Itoa(dx) + "," + strconv.Itoa(dy) + ")c/" + strconv.Itoa(t.Period)
+}
+
+// evolveObject runs the given organisms on an unbounded board for up to the given number of generations
+// until they return to their shape. Returns every phase of the organisms before they did and how far they moved.
+func evolveObject(living []Location, neighbors neighborsSelector, rules func(int, bool) bool, maxPeriod int) ([][]Location, Velocity, bool) {
+	p, err := newPond(Dimensions{}, newBitboard(Location{}, Dimensions{}), neighbors, TopologyUnbounded)
+	if err != nil || len(living) == 0 {
+		return nil, Velocity{}, false
+	}
+	p.SetOrganisms(living)
+
+	origin, dims := BoundingBox(living)
+	hash := stabilityHash(living, origin)
+
+	phases := [][]Location{living}
+	for period := 1; period <= maxPeriod; period++ {
+		if err := BitboardProcessor.Process(p, rules); err != nil {
+			return nil, Velocity{}, false
+		}
+
+		current := p.Living()
+		currentOrigin, currentDims := BoundingBox(current)
+		if len(current) != len(living) || currentDims != dims || stabilityHash(current, currentOrigin) != hash {
+			phases = append(phases, current)
+			continue
+		}
+
+		// Make sure that the shape really is the same
+		displacement := Location{X: currentOrigin.X - origin.X, Y: currentOrigin.Y - origin.Y}
+		same := true
+		for _, loc := range living {
+			if !p.IsAlive(Location{X: loc.X + displacement.X, Y: loc.Y + displacement.Y}) {
+				same = false
+				break
+			}
+		}
+		if !same {
+			phases = append(phases, current)
+			continue
+		}
+
+		return phases, Velocity{Displacement: displacement, Period: period}, true
+	}
+
+	return nil, Velocity{}, false
+}
+
+// DetectSpaceship runs the given organisms on an unbounded board for up to the given number of generations
+// and determines if they return to their shape somewhere else, which makes them a spaceship.
+// The velocity of oscillators and still lifes is stationary.
+func DetectSpaceship(living []Location, neighbors neighborsSelector, rules func(int, bool) bool, maxPeriod int) (Velocity, bool) {
+	_, velocity, periodic := evolveObject(living, neighbors, rules, maxPeriod)
+	return velocity, periodic && velocity.Displacement != (Location{})
+}
+
+// vim: set foldmethod=marker:
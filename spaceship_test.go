@@ -0,0 +1,76 @@
+package life
+
+import "testing"
+
+func TestVelocityString(t *testing.T) {
+	tests := []struct {
+		velocity Velocity
+		expected string
+	}{
+		{Velocity{Displacement: Location{X: 1, Y: 1}, Period: 4}, "c/4 diagonal"},
+		{Velocity{Displacement: Location{X: -2, Y: 0}, Period: 4}, "c/2 orthogonal"},
+		{Velocity{Displacement: Location{X: 0, Y: 2}, Period: 5}, "2c/5 orthogonal"},
+		{Velocity{Displacement: Location{X: 0, Y: -1}, Period: 1}, "c orthogonal"},
+		{Velocity{Displacement: Location{X: -1, Y: 2}, Period: 6}, "(2,1)c/6"},
+		{Velocity{Period: 2}, "stationary"},
+	}
+
+	for _, test := range tests {
+		if actual := test.velocity.String(); actual != test.expected {
+			t.Errorf("Retrieved %q instead of %q\n", actual, test.expected)
+		}
+	}
+}
+
+func TestVelocityDirection(t *testing.T) {
+	tests := map[Location]string{
+		Location{X: 0, Y: 0}:  "stationary",
+		Location{X: 0, Y: -3}: "orthogonal",
+		Location{X: -2, Y: 2}: "diagonal",
+		Location{X: 1, Y: 2}:  "oblique",
+	}
+
+	for displacement, expected := range tests {
+		velocity := Velocity{Displacement: displacement, Period: 4}
+		if actual := velocity.Direction(); actual != expected {
+			t.Errorf("Displacement %v is %s instead of %s\n", displacement, actual, expected)
+		}
+	}
+}
+
+func TestDetectSpaceship(t *testing.T) {
+	glider := Gliders(Dimensions{Width: 4, Height: 4}, Location{X: 10, Y: -3})
+	velocity, found := DetectSpaceship(glider, NeighborsAll, ConwayTester(), 10)
+	if !found {
+		t.Fatal("Glider was not detected as a spaceship")
+	}
+	if velocity.String() != "c/4 diagonal" {
+		t.Fatalf("Glider has velocity %s instead of c/4 diagonal\n", velocity.String())
+	}
+
+	lwss := []Location{{X: 1, Y: 0}, {X: 4, Y: 0}, {X: 0, Y: 1}, {X: 0, Y: 2}, {X: 4, Y: 2},
+		{X: 0, Y: 3}, {X: 1, Y: 3}, {X: 2, Y: 3}, {X: 3, Y: 3}}
+	if velocity, found = DetectSpaceship(lwss, NeighborsAll, ConwayTester(), 10); !found {
+		t.Fatal("Lightweight spaceship was not detected as a spaceship")
+	}
+	if velocity.String() != "c/2 orthogonal" {
+		t.Fatalf("Lightweight spaceship has velocity %s instead of c/2 orthogonal\n", velocity.String())
+	}
+}
+
+func TestDetectSpaceshipOscillator(t *testing.T) {
+	blinker := Blinkers(Dimensions{Width: 3, Height: 3}, Location{})
+	velocity, found := DetectSpaceship(blinker, NeighborsAll, ConwayTester(), 10)
+	if found {
+		t.Fatal("Blinker was detected as a spaceship")
+	}
+	if velocity.Period != 2 {
+		t.Fatalf("Blinker has period %d instead of 2\n", velocity.Period)
+	}
+
+	if _, found := DetectSpaceship([]Location{{X: 0, Y: 0}}, NeighborsAll, ConwayTester(), 10); found {
+		t.Fatal("Dying organism was detected as a spaceship")
+	}
+}
+
+// vim: set foldmethod=marker:
@@ -0,0 +1,201 @@
+package life
+
+import (
+	"bytes"
+	"strconv"
+)
+
+type stabilityState int
+
+// Enumeration of what a simulation can settle into
+const (
+	StabilityEvolving stabilityState = iota
+	StabilityStillLife
+	StabilityCycle
+	StabilityDead
+	StabilitySpaceship
+)
+
+func (t stabilityState) String() string {
+	switch t {
+	case StabilityEvolving:
+		return "Evolving"
+	case StabilityStillLife:
+		return "StillLife"
+	case StabilityCycle:
+		return "Cycle"
+	case StabilityDead:
+		return "Dead"
+	case StabilitySpaceship:
+		return "Spaceship"
+	}
+	return "Unknown"
+}
+
+// Stability describes what a simulation has settled into
+type Stability struct {
+	State        stabilityState
+	Start        int      // The first generation of the still life, cycle or empty board
+	Period       int      // The number of generations before the living organisms repeat
+	Displacement Location // How far the living organisms move every period
+}
+
+// Velocity returns the velocity of the living organisms, which is zero unless they are a spaceship
+func (t Stability) Velocity() Velocity {
+	return Velocity{Displacement: t.Displacement, Period: t.Period}
+}
+
+func (t Stability) String() string {
+	var buf bytes.Buffer
+	buf.WriteString(t.State.String())
+	switch t.State {
+	case StabilityCycle:
+		buf.WriteString(" of period ")
+		buf.WriteString(strconv.Itoa(t.Period))
+	case StabilitySpaceship:
+		buf.WriteString(" ")
+		buf.WriteString(t.Velocity().String())
+	}
+	if t.State != StabilityEvolving {
+		buf.WriteString(" since generation ")
+		buf.WriteString(strconv.Itoa(t.Start))
+	}
+	return buf.String()
+}
+
+// stabilityKey identifies a set of living organisms regardless of where they are
+type stabilityKey struct {
+	hash       uint64
+	population int
+	dims       Dimensions
+}
+
+type stabilitySighting struct {
+	num    int      // The first generation with the key
+	origin Location // The top-left corner of the living organisms in that generation
+	check  uint64   // A second hash of the living organisms, which is independent of the key's
+}
+
+// Once this many generations are remembered, the oldest is forgotten to keep memory in check.
+// Cycles which are longer than this are never detected.
+const stabilityMaxSightings = 4096
+
+// stabilityDetector remembers every generation it observes, by a hash of it, to find the first one which repeats
+type stabilityDetector struct {
+	seen      map[stabilityKey][]stabilitySighting // Generations which collide are kept in the order they were seen
+	order     []stabilityKey                       // The key of each remembered generation, oldest first
+	stability Stability
+}
+
+// mixLocation scrambles the bits of a location so that sums of them rarely collide
+func mixLocation(loc Location) uint64 {
+	// The finalizer of MurmurHash3
+	h := uint64(uint32(loc.X)) | uint64(uint32(loc.Y))<<32
+	h ^= h >> 33
+	h *= 0xff51afd7ed558ccd
+	h ^= h >> 33
+	h *= 0xc4ceb9fe1a85ec53
+	h ^= h >> 33
+	return h
+}
+
+// mixLocationCheck scrambles the bits of a location differently than mixLocation
+func mixLocationCheck(loc Location) uint64 {
+	// The finalizer of SplitMix64, with the coordinates the other way around
+	h := (uint64(uint32(loc.Y)) | uint64(uint32(loc.X))<<32) + 0x9e3779b97f4a7c15
+	h = (h ^ (h >> 30)) * 0xbf58476d1ce4e5b9
+	h = (h ^ (h >> 27)) * 0x94d049bb133111eb
+	return h ^ (h >> 31)
+}
+
+// sumMixed adds up the mixed bits of the living organisms relative to the given origin, in any order
+func sumMixed(living []Location, origin Location, mix func(Location) uint64) uint64 {
+	var sum uint64
+	for _, loc := range living {
+		sum += mix(Location{X: loc.X - origin.X, Y: loc.Y - origin.Y})
+	}
+	return sum
+}
+
+// stabilityHash hashes the living organisms relative to the given origin, in any order
+func stabilityHash(living []Location, origin Location) uint64 {
+	return sumMixed(living, origin, mixLocation)
+}
+
+// stabilityCheck hashes the living organisms like stabilityHash, but independently of it
+func stabilityCheck(living []Location, origin Location) uint64 {
+	return sumMixed(living, origin, mixLocationCheck)
+}
+
+// observe adds the given generation and returns the stability of the simulation
+func (t *stabilityDetector) observe(num int, living []Location) Stability {
+	if t.stability.State != StabilityEvolving {
+		return t.stability
+	}
+
+	if len(living) == 0 {
+		t.stability = Stability{State: StabilityDead, Start: num, Period: 1}
+		return t.stability
+	}
+
+	origin, dims := BoundingBox(living)
+	key := stabilityKey{hash: stabilityHash(living, origin), population: len(living), dims: dims}
+	check := stabilityCheck(living, origin)
+
+	// Organisms whose hash happens to collide are told apart by the second hash,
+	// without keeping a copy of every generation around to compare with
+	var sighting stabilitySighting
+	repeated := false
+	for _, candidate := range t.seen[key] {
+		if candidate.check == check {
+			sighting, repeated = candidate, true
+			break
+		}
+	}
+
+	if repeated {
+		// Since this is the first repeat, the generation it repeats is where the cycle starts
+		t.stability = Stability{
+			State:        StabilityCycle,
+			Start:        sighting.num,
+			Period:       num - sighting.num,
+			Displacement: Location{X: origin.X - sighting.origin.X, Y: origin.Y - sighting.origin.Y},
+		}
+		switch {
+		case t.stability.Displacement != (Location{}):
+			t.stability.State = StabilitySpaceship
+		case t.stability.Period == 1:
+			t.stability.State = StabilityStillLife
+		}
+		t.seen = nil
+		t.order = nil
+	} else {
+		t.remember(key, stabilitySighting{num: num, origin: origin, check: check})
+	}
+
+	return t.stability
+}
+
+// remember adds the sighting of a generation, forgetting the oldest one when there are too many
+func (t *stabilityDetector) remember(key stabilityKey, sighting stabilitySighting) {
+	t.seen[key] = append(t.seen[key], sighting)
+	t.order = append(t.order, key)
+
+	if len(t.order) > stabilityMaxSightings {
+		oldest := t.order[0]
+		t.order = t.order[1:]
+		if sightings := t.seen[oldest]; len(sightings) > 1 {
+			t.seen[oldest] = sightings[1:]
+		} else {
+			delete(t.seen, oldest)
+		}
+	}
+}
+
+func newStabilityDetector() *stabilityDetector {
+	t := new(stabilityDetector)
+	t.seen = make(map[stabilityKey][]stabilitySighting)
+	return t
+}
+
+// vim: set foldmethod=marker:
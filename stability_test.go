@@ -0,0 +1,184 @@
+package life
+
+import (
+	"context"
+	"testing"
+)
+
+func testStability(t *testing.T, seed []Location, generations int, expected Stability) {
+	p, err := newPond(Dimensions{}, newTracker(), NeighborsAll, TopologyUnbounded)
+	if err != nil {
+		t.Fatalf("Unable to create pond: %s\n", err)
+	}
+	p.SetOrganisms(seed)
+
+	detector := newStabilityDetector()
+	actual := detector.observe(0, p.Living())
+	for num := 1; num <= generations; num++ {
+		if err := SimultaneousProcessor.Process(p, ConwayTester()); err != nil {
+			t.Fatalf("Unable to process pond: %s\n", err)
+		}
+		actual = detector.observe(num, p.Living())
+	}
+
+	if actual != expected {
+		t.Fatalf("Detected %s instead of %s\n", actual.String(), expected.String())
+	}
+}
+
+func TestStabilityStillLife(t *testing.T) {
+	// A pre-block becomes a block after a generation
+	seed := []Location{{X: 0, Y: 0}, {X: 1, Y: 0}, {X: 0, Y: 1}}
+	testStability(t, seed, 5, Stability{State: StabilityStillLife, Start: 1, Period: 1})
+}
+
+func TestStabilityOscillator(t *testing.T) {
+	seed := Blinkers(Dimensions{Width: 3, Height: 3}, Location{})
+	testStability(t, seed, 1, Stability{State: StabilityEvolving})
+	testStability(t, seed, 2, Stability{State: StabilityCycle, Start: 0, Period: 2})
+}
+
+func TestStabilitySpaceship(t *testing.T) {
+	seed := Gliders(Dimensions{Width: 4, Height: 4}, Location{})
+	testStability(t, seed, 10, Stability{State: StabilitySpaceship, Start: 0, Period: 4, Displacement: Location{X: 1, Y: 1}})
+}
+
+func TestStabilityDead(t *testing.T) {
+	seed := []Location{{X: 0, Y: 0}, {X: 5, Y: 5}}
+	testStability(t, seed, 3, Stability{State: StabilityDead, Start: 1, Period: 1})
+}
+
+func TestStabilityString(t *testing.T) {
+	stability := Stability{State: StabilityCycle, Start: 3, Period: 15}
+	if expected := "Cycle of period 15 since generation 3"; stability.String() != expected {
+		t.Fatalf("Retrieved %q instead of %q\n", stability.String(), expected)
+	}
+
+	stability = Stability{State: StabilitySpaceship, Start: 3, Period: 4, Displacement: Location{X: 1, Y: -1}}
+	if expected := "Spaceship c/4 diagonal since generation 3"; stability.String() != expected {
+		t.Fatalf("Retrieved %q instead of %q\n", stability.String(), expected)
+	}
+
+	stability = Stability{State: StabilityEvolving}
+	if stability.String() != "Evolving" {
+		t.Fatalf("Retrieved %q instead of %q\n", stability.String(), "Evolving")
+	}
+}
+
+func TestStabilityHash(t *testing.T) {
+	living := []Location{{X: 3, Y: 4}, {X: 5, Y: 4}, {X: 4, Y: 6}}
+	reordered := []Location{{X: 4, Y: 6}, {X: 3, Y: 4}, {X: 5, Y: 4}}
+	moved := []Location{{X: -7, Y: 0}, {X: -5, Y: 0}, {X: -6, Y: 2}}
+
+	expected := stabilityHash(living, Location{X: 3, Y: 4})
+	if stabilityHash(reordered, Location{X: 3, Y: 4}) != expected {
+		t.Error("Hash depends on the order of the organisms")
+	}
+	if stabilityHash(moved, Location{X: -7, Y: 0}) != expected {
+		t.Error("Hash depends on the location of the organisms")
+	}
+	if stabilityHash(living[:2], Location{X: 3, Y: 4}) == expected {
+		t.Error("Hash does not depend on the organisms")
+	}
+
+	// Generations 9 and 13 of a pentadecathlon have the same size and population
+	pentadecathlon9 := []Location{{X: 2, Y: 0}, {X: 5, Y: 0}, {X: 10, Y: 0}, {X: 13, Y: 0}, {X: 0, Y: 1}, {X: 1, Y: 1}, {X: 2, Y: 1},
+		{X: 5, Y: 1}, {X: 6, Y: 1}, {X: 7, Y: 1}, {X: 8, Y: 1}, {X: 9, Y: 1}, {X: 10, Y: 1}, {X: 13, Y: 1}, {X: 14, Y: 1}, {X: 15, Y: 1},
+		{X: 2, Y: 2}, {X: 5, Y: 2}, {X: 10, Y: 2}, {X: 13, Y: 2}}
+	pentadecathlon13 := []Location{{X: 1, Y: 0}, {X: 4, Y: 0}, {X: 5, Y: 0}, {X: 10, Y: 0}, {X: 11, Y: 0}, {X: 14, Y: 0}, {X: 0, Y: 1},
+		{X: 4, Y: 1}, {X: 5, Y: 1}, {X: 6, Y: 1}, {X: 9, Y: 1}, {X: 10, Y: 1}, {X: 11, Y: 1}, {X: 15, Y: 1}, {X: 1, Y: 2}, {X: 4, Y: 2},
+		{X: 5, Y: 2}, {X: 10, Y: 2}, {X: 11, Y: 2}, {X: 14, Y: 2}}
+	if stabilityHash(pentadecathlon9, Location{}) == stabilityHash(pentadecathlon13, Location{}) {
+		t.Error("Different shapes of the same size and population have the same hash")
+	}
+	if stabilityCheck(pentadecathlon9, Location{}) == stabilityCheck(pentadecathlon13, Location{}) {
+		t.Error("Different shapes of the same size and population have the same second hash")
+	}
+
+	// The second hash is not simply another form of the first
+	if stabilityCheck(moved, Location{X: -7, Y: 0}) != stabilityCheck(living, Location{X: 3, Y: 4}) ||
+		stabilityCheck(living, Location{X: 3, Y: 4}) == expected {
+		t.Error("Second hash depends on the location of the organisms or matches the first")
+	}
+}
+
+func TestStabilityHashCollision(t *testing.T) {
+	blinker := []Location{{X: 0, Y: 1}, {X: 1, Y: 1}, {X: 2, Y: 1}}
+	diagonal := []Location{{X: 0, Y: 0}, {X: 1, Y: 1}, {X: 2, Y: 2}}
+
+	// Pretend that a different shape of the same size and population was seen with the same hash
+	detector := newStabilityDetector()
+	origin, dims := BoundingBox(blinker)
+	key := stabilityKey{hash: stabilityHash(blinker, origin), population: len(blinker), dims: dims}
+	detector.remember(key, stabilitySighting{num: 0, origin: origin, check: stabilityCheck(diagonal, Location{})})
+
+	if stability := detector.observe(1, blinker); stability.State != StabilityEvolving {
+		t.Fatalf("Detected %s from a hash collision\n", stability.String())
+	}
+	if stability := detector.observe(2, Translate(blinker, Location{X: 5})); stability.State != StabilitySpaceship || stability.Start != 1 {
+		t.Fatalf("Detected %s instead of the repeat of generation 1\n", stability.String())
+	}
+}
+
+func TestStabilityForgets(t *testing.T) {
+	detector := newStabilityDetector()
+	for num := 0; num <= stabilityMaxSightings; num++ {
+		detector.observe(num, []Location{{X: 0, Y: 0}, {X: num + 2, Y: 0}})
+	}
+	if len(detector.order) != stabilityMaxSightings || len(detector.seen) != stabilityMaxSightings {
+		t.Fatalf("Detector remembers %d generations by %d keys\n", len(detector.order), len(detector.seen))
+	}
+
+	// The first generation was forgotten, so seeing it again is not a repeat
+	if stability := detector.observe(stabilityMaxSightings+1, []Location{{X: 0, Y: 0}, {X: 2, Y: 0}}); stability.State != StabilityEvolving {
+		t.Fatalf("Detected %s of a forgotten generation\n", stability.String())
+	}
+	if stability := detector.observe(stabilityMaxSightings+2, []Location{{X: 0, Y: 0}, {X: stabilityMaxSightings + 2, Y: 0}}); stability.State != StabilityCycle {
+		t.Fatalf("Detected %s instead of a cycle\n", stability.String())
+	}
+}
+
+func TestLifeHaltWhenStable(t *testing.T) {
+	life, err := New(
+		Dimensions{Height: 8, Width: 8},
+		NeighborsAll,
+		TopologyTorus,
+		func(Dimensions, Location) []Location {
+			return Gliders(Dimensions{Height: 4, Width: 4}, Location{})
+		},
+		ConwayTester(),
+		SimultaneousProcessor)
+	if err != nil {
+		t.Fatalf("Unable to create strategy: %s\n", err)
+	}
+
+	if stability := life.Stability(); stability.State != StabilityEvolving {
+		t.Fatalf("Detected %s without detecting stability\n", stability.String())
+	}
+
+	life.DetectStability(true)
+
+	listener := make(chan *Generation)
+	finished := make(chan error, 1)
+	go func() {
+		finished <- life.Run(context.Background(), listener)
+	}()
+
+	var last *Generation
+	for gen := range listener {
+		last = gen
+	}
+	if err := <-finished; err != nil {
+		t.Fatalf("Run returned %s\n", err)
+	}
+
+	stability := life.Stability()
+	if stability.State == StabilityEvolving {
+		t.Fatal("Run halted before the simulation was stable")
+	}
+	if last.Num != stability.Start+stability.Period {
+		t.Fatalf("Run halted at generation %d instead of %d\n", last.Num, stability.Start+stability.Period)
+	}
+}
+
+// vim: set foldmethod=marker:
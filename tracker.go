@@ -1,5 +1,16 @@
 package life
 
+// cellStore keeps track of the locations of the living organisms
+type cellStore interface {
+	Set(Location) bool
+	Remove(Location) bool
+	Test(Location) bool
+	GetAll() []Location
+	Count() int
+	Equals(cellStore) bool
+	Clone() cellStore
+}
+
 type trackerAddOp struct {
 	loc  Location
 	resp chan bool
@@ -29,6 +40,7 @@ type tracker struct {
 	trackerTest   chan *trackerTestOp
 	trackerGetAll chan *trackerGetAllOp
 	trackerCount  chan *trackerCountOp
+	trackerStop   chan bool
 }
 
 func (t *tracker) living() {
@@ -57,10 +69,11 @@ func (t *tracker) living() {
 					removed = true
 					count--
 
-					// TODO Delete the row if it has no children?
-					// if len(livingMap[remove.loc.Y]) <= 0 {
-					// 	delete(livingMap, remove.loc.Y)
-					// }
+					// Delete the row if it has no children so
+					// that an unbounded pond doesn't leak empty rows
+					if len(livingMap[remove.loc.Y]) <= 0 {
+						delete(livingMap, remove.loc.Y)
+					}
 				}
 			}
 			remove.resp <- removed
@@ -80,6 +93,8 @@ func (t *tracker) living() {
 			getall.resp <- all
 		case countOp := <-t.trackerCount:
 			countOp.resp <- count
+		case <-t.trackerStop:
+			return
 		}
 	}
 }
@@ -124,7 +139,7 @@ func (t *tracker) Count() int {
 	return val
 }
 
-func (t *tracker) Equals(rhs *tracker) bool {
+func (t *tracker) Equals(rhs cellStore) bool {
 	if t.Count() != rhs.Count() {
 		return false
 	}
@@ -138,7 +153,7 @@ func (t *tracker) Equals(rhs *tracker) bool {
 	return true
 }
 
-func (t *tracker) Clone() *tracker {
+func (t *tracker) Clone() cellStore {
 	shadow := newTracker()
 
 	for _, loc := range t.GetAll() {
@@ -148,6 +163,11 @@ func (t *tracker) Clone() *tracker {
 	return shadow
 }
 
+// stop ends the goroutine which keeps track of the organisms. The tracker cannot be used after it is stopped.
+func (t *tracker) stop() {
+	close(t.trackerStop)
+}
+
 func newTracker() *tracker {
 	t := new(tracker)
 
@@ -156,6 +176,7 @@ func newTracker() *tracker {
 	t.trackerTest = make(chan *trackerTestOp)
 	t.trackerGetAll = make(chan *trackerGetAllOp)
 	t.trackerCount = make(chan *trackerCountOp)
+	t.trackerStop = make(chan bool)
 
 	go t.living()
 
@@ -0,0 +1,162 @@
+package life
+
+import (
+	"sort"
+)
+
+// BoundingBox returns the top-left corner and the size of
+// the smallest rectangle which contains all of the given locations
+func BoundingBox(locations []Location) (Location, Dimensions) {
+	if len(locations) == 0 {
+		return Location{}, Dimensions{}
+	}
+
+	min := locations[0]
+	max := locations[0]
+	for _, loc := range locations {
+		if loc.X < min.X {
+			min.X = loc.X
+		}
+		if loc.Y < min.Y {
+			min.Y = loc.Y
+		}
+		if loc.X > max.X {
+			max.X = loc.X
+		}
+		if loc.Y > max.Y {
+			max.Y = loc.Y
+		}
+	}
+
+	return min, Dimensions{Width: max.X - min.X + 1, Height: max.Y - min.Y + 1}
+}
+
+// Translate moves every location by the given offset
+func Translate(living []Location, offset Location) []Location {
+	moved := make([]Location, len(living))
+	for i, loc := range living {
+		moved[i] = Location{X: loc.X + offset.X, Y: loc.Y + offset.Y}
+	}
+	return moved
+}
+
+// Normalize moves the locations so that the top-left corner of their bounding box is at the origin
+func Normalize(living []Location) []Location {
+	origin, _ := BoundingBox(living)
+	return Translate(living, Location{X: -origin.X, Y: -origin.Y})
+}
+
+// Crop returns only the locations which are within the rectangle with the given top-left corner and size
+func Crop(living []Location, origin Location, dims Dimensions) []Location {
+	cropped := make([]Location, 0)
+	for _, loc := range living {
+		if loc.X >= origin.X && loc.X < origin.X+dims.Width && loc.Y >= origin.Y && loc.Y < origin.Y+dims.Height {
+			cropped = append(cropped, loc)
+		}
+	}
+	return cropped
+}
+
+// transform maps every location and then moves them back so that the
+// top-left corner of their bounding box is where it was before
+func transform(living []Location, mapping func(Location) Location) []Location {
+	mapped := make([]Location, len(living))
+	for i, loc := range living {
+		mapped[i] = mapping(loc)
+	}
+
+	before, _ := BoundingBox(living)
+	after, _ := BoundingBox(mapped)
+	return Translate(mapped, Location{X: before.X - after.X, Y: before.Y - after.Y})
+}
+
+// Rotate90 rotates the locations a quarter turn clockwise, keeping the top-left corner of their bounding box in place
+func Rotate90(living []Location) []Location {
+	return transform(living, func(loc Location) Location { return Location{X: -loc.Y, Y: loc.X} })
+}
+
+// Rotate180 rotates the locations a half turn, keeping the top-left corner of their bounding box in place
+func Rotate180(living []Location) []Location {
+	return transform(living, func(loc Location) Location { return Location{X: -loc.X, Y: -loc.Y} })
+}
+
+// Rotate270 rotates the locations a quarter turn counterclockwise, keeping the top-left corner of their bounding box in place
+func Rotate270(living []Location) []Location {
+	return transform(living, func(loc Location) Location { return Location{X: loc.Y, Y: -loc.X} })
+}
+
+// FlipHorizontal mirrors the locations from left to right, keeping the top-left corner of their bounding box in place
+func FlipHorizontal(living []Location) []Location {
+	return transform(living, func(loc Location) Location { return Location{X: -loc.X, Y: loc.Y} })
+}
+
+// FlipVertical mirrors the locations from top to bottom, keeping the top-left corner of their bounding box in place
+func FlipVertical(living []Location) []Location {
+	return transform(living, func(loc Location) Location { return Location{X: loc.X, Y: -loc.Y} })
+}
+
+// FlipDiagonal mirrors the locations across the diagonal from the top-left to the bottom-right,
+// keeping the top-left corner of their bounding box in place
+func FlipDiagonal(living []Location) []Location {
+	return transform(living, func(loc Location) Location { return Location{X: loc.Y, Y: loc.X} })
+}
+
+// FlipAntiDiagonal mirrors the locations across the diagonal from the top-right to the bottom-left,
+// keeping the top-left corner of their bounding box in place
+func FlipAntiDiagonal(living []Location) []Location {
+	return transform(living, func(loc Location) Location { return Location{X: -loc.Y, Y: -loc.X} })
+}
+
+// Symmetries returns the locations in all eight of their orientations:
+// as they are, rotated three ways and flipped four ways
+func Symmetries(living []Location) [][]Location {
+	return [][]Location{
+		living,
+		Rotate90(living),
+		Rotate180(living),
+		Rotate270(living),
+		FlipHorizontal(living),
+		FlipVertical(living),
+		FlipDiagonal(living),
+		FlipAntiDiagonal(living),
+	}
+}
+
+// sortLocations sorts the locations by row and then by column
+func sortLocations(living []Location) {
+	sort.Slice(living, func(i, j int) bool {
+		if living[i].Y != living[j].Y {
+			return living[i].Y < living[j].Y
+		}
+		return living[i].X < living[j].X
+	})
+}
+
+// lessLocations tests if the first sorted locations come before the second
+func lessLocations(lhs, rhs []Location) bool {
+	for i := 0; i < len(lhs) && i < len(rhs); i++ {
+		if lhs[i].Y != rhs[i].Y {
+			return lhs[i].Y < rhs[i].Y
+		}
+		if lhs[i].X != rhs[i].X {
+			return lhs[i].X < rhs[i].X
+		}
+	}
+	return len(lhs) < len(rhs)
+}
+
+// Canonical returns the normalized and sorted orientation of the locations which comes first.
+// A pattern has the same canonical form no matter where it is or how it was rotated or flipped.
+func Canonical(living []Location) []Location {
+	var canonical []Location
+	for _, oriented := range Symmetries(living) {
+		normalized := Normalize(oriented)
+		sortLocations(normalized)
+		if canonical == nil || lessLocations(normalized, canonical) {
+			canonical = normalized
+		}
+	}
+	return canonical
+}
+
+// vim: set foldmethod=marker:
@@ -0,0 +1,119 @@
+package life
+
+import "testing"
+
+// The glider which Gliders generates, travelling southeast
+//
+//	-0-
+//	--0
+//	000
+var transformGlider = []Location{{X: 11, Y: 20}, {X: 12, Y: 21}, {X: 10, Y: 22}, {X: 11, Y: 22}, {X: 12, Y: 22}}
+
+func TestBoundingBox(t *testing.T) {
+	origin, dims := BoundingBox(transformGlider)
+	if origin != (Location{X: 10, Y: 20}) {
+		t.Errorf("Bounding box starts at %v instead of [10,20]\n", origin)
+	}
+	if dims != (Dimensions{Width: 3, Height: 3}) {
+		t.Errorf("Bounding box is %v instead of 3x3\n", dims)
+	}
+
+	if origin, dims := BoundingBox([]Location{}); origin != (Location{}) || dims != (Dimensions{}) {
+		t.Errorf("Bounding box of nothing is %v at %v\n", dims, origin)
+	}
+}
+
+func TestTranslateNormalize(t *testing.T) {
+	moved := Translate(transformGlider, Location{X: -10, Y: -20})
+	expected := []Location{{X: 1, Y: 0}, {X: 2, Y: 1}, {X: 0, Y: 2}, {X: 1, Y: 2}, {X: 2, Y: 2}}
+	testLocationsMatch(t, expected, moved)
+	testLocationsMatch(t, expected, Normalize(transformGlider))
+}
+
+func TestCrop(t *testing.T) {
+	cropped := Crop(transformGlider, Location{X: 11, Y: 21}, Dimensions{Width: 2, Height: 2})
+	testLocationsMatch(t, []Location{{X: 12, Y: 21}, {X: 11, Y: 22}, {X: 12, Y: 22}}, cropped)
+}
+
+func TestRotateFlip(t *testing.T) {
+	tests := []struct {
+		name      string
+		transform func([]Location) []Location
+		expected  []Location
+	}{
+		// 0--
+		// 0-0
+		// 00-
+		{"Rotate90", Rotate90, []Location{{X: 10, Y: 20}, {X: 10, Y: 21}, {X: 12, Y: 21}, {X: 10, Y: 22}, {X: 11, Y: 22}}},
+		// 000
+		// 0--
+		// -0-
+		{"Rotate180", Rotate180, []Location{{X: 10, Y: 20}, {X: 11, Y: 20}, {X: 12, Y: 20}, {X: 10, Y: 21}, {X: 11, Y: 22}}},
+		// -00
+		// 0-0
+		// --0
+		{"Rotate270", Rotate270, []Location{{X: 11, Y: 20}, {X: 12, Y: 20}, {X: 10, Y: 21}, {X: 12, Y: 21}, {X: 12, Y: 22}}},
+		// -0-
+		// 0--
+		// 000
+		{"FlipHorizontal", FlipHorizontal, []Location{{X: 11, Y: 20}, {X: 10, Y: 21}, {X: 10, Y: 22}, {X: 11, Y: 22}, {X: 12, Y: 22}}},
+		// 000
+		// --0
+		// -0-
+		{"FlipVertical", FlipVertical, []Location{{X: 10, Y: 20}, {X: 11, Y: 20}, {X: 12, Y: 20}, {X: 12, Y: 21}, {X: 11, Y: 22}}},
+		// --0
+		// 0-0
+		// -00
+		{"FlipDiagonal", FlipDiagonal, []Location{{X: 12, Y: 20}, {X: 10, Y: 21}, {X: 12, Y: 21}, {X: 11, Y: 22}, {X: 12, Y: 22}}},
+		// 00-
+		// 0-0
+		// 0--
+		{"FlipAntiDiagonal", FlipAntiDiagonal, []Location{{X: 10, Y: 20}, {X: 11, Y: 20}, {X: 10, Y: 21}, {X: 12, Y: 21}, {X: 10, Y: 22}}},
+	}
+
+	for _, test := range tests {
+		t.Logf("%s\n", test.name)
+		testLocationsMatch(t, test.expected, test.transform(transformGlider))
+	}
+
+	// Four quarter turns are where we started
+	rotated := transformGlider
+	for i := 0; i < 4; i++ {
+		rotated = Rotate90(rotated)
+	}
+	testLocationsMatch(t, transformGlider, rotated)
+}
+
+func TestSymmetries(t *testing.T) {
+	symmetries := Symmetries(transformGlider)
+	if len(symmetries) != 8 {
+		t.Fatalf("Found %d symmetries instead of 8\n", len(symmetries))
+	}
+
+	// A block looks the same in every orientation
+	block := Blocks(Dimensions{Width: 5, Height: 5}, Location{})
+	for _, oriented := range Symmetries(block) {
+		testLocationsMatch(t, block, oriented)
+	}
+}
+
+func TestCanonical(t *testing.T) {
+	expected := Canonical(transformGlider)
+	for _, oriented := range Symmetries(transformGlider) {
+		actual := Canonical(Translate(oriented, Location{X: -40, Y: 7}))
+		if len(actual) != len(expected) {
+			t.Fatalf("Canonical form has %d locations instead of %d\n", len(actual), len(expected))
+		}
+		for i := range expected {
+			if actual[i] != expected[i] {
+				t.Fatalf("Canonical form %v does not match %v\n", actual, expected)
+			}
+		}
+	}
+
+	if origin, _ := BoundingBox(expected); origin != (Location{}) {
+		t.Fatalf("Canonical form starts at %v instead of the origin\n", origin)
+	}
+}
+
+// vim: set foldmethod=marker: